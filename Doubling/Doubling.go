@@ -17,19 +17,73 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"math/bits"
+	"os"
+	"os/signal"
 	"runtime"
+	"strings"
 	"sync/atomic"
 	"time"
 )
 
 // Configuration centralise les paramètres configurables.
 type Configuration struct {
-	M       int           // Calcul de Fibonacci(M)
-	Timeout time.Duration // Durée maximale d'exécution
+	M               int           // Calcul de Fibonacci(M)
+	IndexSet        bool          // M a été fourni explicitement (-n ou -config)
+	Timeout         time.Duration // Durée maximale d'exécution
+	Deadline        time.Time     // Échéance absolue, prioritaire sur Timeout si définie
+	OutputFile      string        // Fichier où écrire le résultat complet (optionnel)
+	Format          string        // Format du fichier de sortie ; déduit de l'extension si vide
+	Compress        bool          // Compresse le fichier de sortie avec gzip (implicite pour .gz)
+	PhiDigits       int           // Si > 0, calcule φ avec ce nombre de décimales au lieu de F(M)
+	Offset          int           // Convention d'indexation de M (0 : F(0)=0, 1 : F(1)=F(2)=1)
+	InputFile       string        // Fichier d'indices à calculer par lot (optionnel)
+	Watch           bool          // Recalcule le lot à chaque modification de InputFile
+	Histogram       bool          // Affiche l'histogramme des chiffres décimaux du résultat
+	Lucas           bool          // Calcule le nombre de Lucas L(M) au lieu de F(M)
+	Mod             string        // Si non vide, calcule le résultat modulo cet entier (> 0)
+	Binary          bool          // Affiche aussi le résultat en base 2, préfixé par 0b
+	Base            int           // Si non nul, affiche aussi le résultat dans cette base (2..36)
+	CacheDir        string        // Répertoire du cache disque des résultats (désactivé si vide)
+	Pisano          uint64        // Si non nul, calcule la période de Pisano π(Pisano)
+	Stdin           bool          // Lit les indices sur l'entrée standard
+	Version         bool          // Affiche la version du programme et s'arrête
+	Digits          bool          // Affiche seulement le nombre de chiffres de F(n)
+	Sum             bool          // Calcule F(0) + … + F(M-1) au lieu de F(M)
+	List            bool          // Affiche toute la suite jusqu'à F(M), un terme par ligne
+	MaxMemory       string        // Budget mémoire du calcul, ex. "2GiB" (illimité si vide)
+	Repeat          int           // Si > 0, répète le calcul et affiche des statistiques de durée
+	ConfigFile      string        // Fichier JSON de valeurs par défaut des options (optionnel)
+	Quiet           bool          // N'écrit que le résultat brut sur la sortie standard
+	Group           bool          // Affiche le résultat complet avec séparateurs de milliers
+	GroupSep        string        // Séparateur de milliers utilisé par -group
+	Lang            string        // Langue dont -group suit l'usage : fr, de ou en (remplace -group-sep)
+	Ratio           bool          // Affiche F(M)/F(M-1), convergent de φ, au lieu de F(M)
+	RatioDigits     int           // Nombre de décimales du développement de F(M)/F(M-1)
+	OutputDigits    int           // Chiffres de tête et de queue affichés en décimal (0 : tous, < 0 : aucun)
+	Zeckendorf      string        // Entier à décomposer en somme de Fibonacci (remplace le calcul de F(M))
+	Gcd             string        // Couple « m,n » pour lequel vérifier pgcd(F(m), F(n)) = F(pgcd(m, n))
+	JSONPretty      bool          // Indente la sortie au format json
+	Checkpoint      string        // Fichier du point de reprise enregistré pendant le calcul
+	CheckpointEvery int           // Nombre d'itérations du doublement entre deux points de reprise
+	Resume          string        // Point de reprise à partir duquel reprendre le calcul
+	MaxProcs        int           // Nombre de processeurs utilisables (0 : tous)
+	SelfTest        bool          // Vérifie les calculs par rapport à un oracle et s'arrête
+	FibCode         string        // Entier dont afficher le code de Fibonacci
+	FibDecode       string        // Code de Fibonacci à décoder
+	Clipboard       bool          // Copie le résultat dans le presse-papiers
+	CPUProfile      string        // Fichier du profil processeur (pprof)
+	MemProfile      string        // Fichier du profil du tas écrit à la fin (pprof)
+	Trace           string        // Fichier de la trace d'exécution (go tool trace)
+
+	// Nombre de chiffres significatifs de la notation scientifique affichée
+	SciPrecision int
 }
 
 // DefaultConfig retourne une configuration par défaut.
@@ -38,6 +92,233 @@ func DefaultConfig() Configuration {
 		// Par défaut, on calcule Fibonacci(100) (modifiable selon les besoins)
 		M:       100000000,
 		Timeout: 5 * time.Minute, // Timeout de 5 minutes
+
+		SciPrecision:    6,   // Mantisse à 6 chiffres significatifs, ex. 3.54224×10²⁰
+		RatioDigits:     50,  // Décimales affichées pour -ratio
+		GroupSep:        ",", // 832040 s'affiche 832,040 avec -group
+		OutputDigits:    -1,  // Pas d'affichage décimal par défaut
+		CheckpointEvery: 1,   // Point de reprise à chaque itération
+	}
+}
+
+// parseFlags construit la configuration à partir des valeurs par défaut, du
+// fichier -config éventuel puis des options de la ligne de commande.
+func parseFlags() (Configuration, error) {
+	return parseFlagSet(flag.CommandLine, os.Args[1:])
+}
+
+// parseFlagSet déclare les options dans fs et analyse args (voir parseFlags).
+func parseFlagSet(fs *flag.FlagSet, args []string) (Configuration, error) {
+	config := DefaultConfig()
+	fs.Var(indexFlag{&config.M}, "n", "Indice `n` de Fibonacci(n) à calculer (accepte 1_000_000 et 1e6)")
+	fs.DurationVar(&config.Timeout, "timeout", config.Timeout, "Durée maximale d'exécution")
+	fs.Func("deadline", "Échéance absolue au format RFC 3339, ex. 2024-01-02T15:04:05Z (prioritaire sur -timeout)", func(s string) error {
+		deadline, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("échéance invalide %q : format RFC 3339 attendu", s)
+		}
+		config.Deadline = deadline
+		return nil
+	})
+	fs.StringVar(&config.OutputFile, "out", "", "Fichier où écrire le résultat complet (.json, .txt, .bin)")
+	fs.BoolVar(&config.Compress, "compress", false, "Compresse le fichier -out avec gzip (implicite si le chemin se termine par .gz)")
+	fs.StringVar(&config.Format, "format", "", "Format du fichier de sortie (json, text, binary, bits) ; déduit de l'extension si absent")
+	fs.IntVar(&config.PhiDigits, "phi-digits", 0, "Calcule le nombre d'or φ avec ce nombre de décimales via F(k+1)/F(k)")
+	fs.IntVar(&config.Offset, "offset", 0, "Convention d'indexation : 0 (F(0)=0, défaut) ou 1 (F(1)=F(2)=1, style OEIS)")
+	fs.StringVar(&config.InputFile, "input-file", "", "Fichier d'indices (séparés par des espaces ou des retours à la ligne) à calculer par lot")
+	fs.BoolVar(&config.Watch, "watch", false, "Avec -input-file, recalcule à chaque modification du fichier")
+	fs.BoolVar(&config.Histogram, "digit-histogram", false, "Affiche la distribution des chiffres décimaux de F(n)")
+	fs.BoolVar(&config.Lucas, "lucas", false, "Calcule le nombre de Lucas L(n) au lieu de F(n)")
+	fs.StringVar(&config.Mod, "mod", "", "Calcule le résultat modulo m (entier > 0), ce qui autorise des n gigantesques")
+	fs.BoolVar(&config.Binary, "binary", false, "Affiche aussi le résultat en binaire (préfixe 0b)")
+	fs.IntVar(&config.Base, "base", 0, "Affiche aussi le résultat dans la base indiquée (2..36), prioritaire sur -binary")
+	fs.StringVar(&config.CacheDir, "cache-dir", "", "Répertoire du cache disque des résultats entre deux exécutions")
+	fs.Uint64Var(&config.Pisano, "pisano", 0, "Calcule la période de Pisano π(m) de F(n) mod m")
+	fs.BoolVar(&config.Stdin, "stdin", false, "Lit les indices sur l'entrée standard et écrit chaque résultat au format -format")
+	fs.IntVar(&config.SciPrecision, "sci-precision", config.SciPrecision, "Nombre de chiffres significatifs de la notation scientifique affichée")
+	fs.BoolVar(&config.Ratio, "ratio", false, "Affiche F(n)/F(n-1) et son développement décimal, qui converge vers φ")
+	fs.IntVar(&config.RatioDigits, "ratio-digits", config.RatioDigits, "Nombre de décimales du développement de F(n)/F(n-1)")
+	fs.IntVar(&config.Repeat, "repeat", 0, "Banc d'essai : répète le calcul K fois et affiche min/médiane/moyenne/max/écart type")
+	fs.StringVar(&config.MaxMemory, "max-memory", "", "Refuse le calcul si son pic mémoire estimé dépasse ce budget (ex. 512MiB, 2GiB)")
+	fs.BoolVar(&config.List, "list", false, "Affiche toute la suite jusqu'à F(n), un terme par ligne")
+	fs.BoolVar(&config.Sum, "sum", false, "Calcule la somme F(0) + … + F(n-1) = F(n+1) - 1 au lieu de F(n)")
+	fs.BoolVar(&config.Digits, "digits", false, "Affiche seulement le nombre de chiffres décimaux de F(n), sans le calculer")
+	fs.BoolVar(&config.Version, "version", false, "Affiche la version du programme et s'arrête")
+	fs.BoolVar(&config.Group, "group", false, fmt.Sprintf("Affiche le résultat complet avec séparateurs de milliers (jusqu'à %d chiffres)", maxGroupedDigits))
+	fs.StringVar(&config.GroupSep, "group-sep", config.GroupSep, "Séparateur de milliers utilisé par -group")
+	fs.StringVar(&config.Lang, "lang", "", "Séparateur de milliers de -group selon la langue : fr (espace), de (point) ou en (virgule)")
+	fs.IntVar(&config.OutputDigits, "output-digits", config.OutputDigits, "Affiche les `K` premiers et derniers chiffres décimaux du résultat (0 : tous)")
+	fs.StringVar(&config.Zeckendorf, "zeckendorf", "", "Affiche la représentation de Zeckendorf de l'entier `x` au lieu de calculer F(n)")
+	fs.StringVar(&config.Gcd, "gcd", "", "Vérifie pgcd(F(m), F(n)) = F(pgcd(m, n)) pour le couple `m,n`")
+	fs.BoolVar(&config.JSONPretty, "json-pretty", false, "Indente la sortie au format json (sans effet sur les autres formats)")
+	fs.StringVar(&config.Checkpoint, "checkpoint", "", "Enregistre régulièrement l'état du calcul dans ce fichier pour pouvoir le reprendre")
+	fs.IntVar(&config.CheckpointEvery, "checkpoint-every", config.CheckpointEvery, "Nombre d'itérations du doublement entre deux points de reprise")
+	fs.StringVar(&config.Resume, "resume", "", "Reprend le calcul depuis ce point de reprise (enregistré ensuite au même endroit, sauf -checkpoint)")
+	fs.IntVar(&config.MaxProcs, "max-procs", 0, "Nombre maximal de processeurs utilisés simultanément (GOMAXPROCS) ; 0 pour tous")
+	fs.BoolVar(&config.SelfTest, "selftest", false, "Vérifie chaque chemin de calcul par rapport à un oracle et s'arrête (code de sortie non nul en cas d'écart)")
+	fs.StringVar(&config.FibCode, "fibcode", "", "Affiche le code de Fibonacci de l'entier `x` (représentation de Zeckendorf terminée par 11)")
+	fs.StringVar(&config.FibDecode, "fibdecode", "", "Affiche l'entier dont `code` est le code de Fibonacci")
+	fs.BoolVar(&config.Clipboard, "clipboard", false, "Copie le résultat décimal (tronqué par -output-digits) dans le presse-papiers")
+	fs.StringVar(&config.CPUProfile, "cpuprofile", "", "Enregistre un profil processeur (pprof) dans ce fichier")
+	fs.StringVar(&config.MemProfile, "memprofile", "", "Écrit un profil du tas (pprof) dans ce fichier à la fin de l'exécution")
+	fs.StringVar(&config.Trace, "trace", "", "Enregistre une trace d'exécution (go tool trace) dans ce fichier")
+	fs.BoolVar(&config.Quiet, "quiet", false, "N'écrit que le résultat (au format -format) sur la sortie standard ; diagnostics et erreurs sur la sortie d'erreur")
+	fs.StringVar(&config.ConfigFile, "config", "", "Fichier JSON de valeurs des options (clés : noms des options), prioritaire sur les défauts mais pas sur la ligne de commande")
+	if err := fs.Parse(args); err != nil {
+		return config, err
+	}
+	if config.ConfigFile != "" {
+		if err := applyConfigFile(fs, config.ConfigFile); err != nil {
+			return config, err
+		}
+	}
+	fs.Visit(func(f *flag.Flag) { config.IndexSet = config.IndexSet || f.Name == "n" })
+	return config, nil
+}
+
+// Validate vérifie la cohérence des options qui ne dépendent pas du calcul.
+func (c Configuration) Validate() error {
+	if c.Watch && c.InputFile == "" {
+		return fmt.Errorf("-watch nécessite -input-file")
+	}
+	if !c.Deadline.IsZero() && !c.Deadline.After(time.Now()) {
+		return fmt.Errorf("l'échéance -deadline %s est déjà passée", c.Deadline.Format(time.RFC3339))
+	}
+	if _, ok := localeSeparators[c.Lang]; c.Lang != "" && !ok {
+		return fmt.Errorf("langue -lang inconnue %q (attendu : fr, de ou en)", c.Lang)
+	}
+	if c.Checkpoint != "" || c.Resume != "" {
+		if c.Mod != "" || c.Stdin || c.InputFile != "" || c.Repeat > 0 {
+			return fmt.Errorf("-checkpoint et -resume sont incompatibles avec -mod, -stdin, -input-file et -repeat")
+		}
+		if c.CheckpointEvery < 1 {
+			return fmt.Errorf("-checkpoint-every doit être supérieur ou égal à 1 (reçu %d)", c.CheckpointEvery)
+		}
+	}
+	if modes := c.standaloneModes(); len(modes) > 1 {
+		return fmt.Errorf("%s sont incompatibles", strings.Join(modes, " et "))
+	} else if len(modes) == 1 && (c.IndexSet || c.Lucas || c.Mod != "" || c.Sum || c.Digits || c.List ||
+		c.Ratio || c.PhiDigits != 0 || c.Pisano != 0 || c.Repeat > 0 || c.Stdin || c.InputFile != "") {
+		return fmt.Errorf("%s est incompatible avec -n, -lucas, -mod, -sum, -digits, -list, -ratio, -phi-digits, -pisano, -repeat, -stdin et -input-file", modes[0])
+	}
+	if c.MaxProcs < 0 {
+		return fmt.Errorf("-max-procs doit être positif ou nul (reçu %d)", c.MaxProcs)
+	}
+	if c.Repeat < 0 {
+		return fmt.Errorf("-repeat doit être positif ou nul (reçu %d)", c.Repeat)
+	}
+	if c.MaxMemory != "" {
+		if _, err := parseByteSize(c.MaxMemory); err != nil {
+			return err
+		}
+	}
+	if c.Compress && c.OutputFile == "" {
+		return fmt.Errorf("-compress nécessite -out")
+	}
+	if c.Stdin && c.InputFile != "" {
+		return fmt.Errorf("-stdin est incompatible avec -input-file")
+	}
+	if c.InputFile != "" && (c.OutputFile != "" || c.Format != "" || c.Quiet || c.Repeat > 0 ||
+		c.Digits || c.List || c.Ratio || c.PhiDigits != 0 || c.Pisano != 0) {
+		return fmt.Errorf("-input-file est incompatible avec -out, -format, -quiet, -repeat, -digits, -list, -ratio, -phi-digits et -pisano (utilisez -stdin < fichier pour un résultat par indice au format -format)")
+	}
+	if c.Base != 0 && (c.Base < 2 || c.Base > 36) {
+		return fmt.Errorf("base invalide : %d (attendu : entre 2 et 36)", c.Base)
+	}
+	if c.Format == FormatBits && (c.Binary || c.Base != 0) {
+		return fmt.Errorf("-format bits est incompatible avec -binary et -base")
+	}
+	if c.Digits && (c.Lucas || c.Mod != "" || c.Sum) {
+		return fmt.Errorf("-digits est incompatible avec -lucas, -mod et -sum")
+	}
+	if c.SciPrecision < 1 {
+		return fmt.Errorf("-sci-precision doit être supérieur ou égal à 1 (reçu %d)", c.SciPrecision)
+	}
+	if c.List && (c.Lucas || c.Mod != "" || c.Sum || c.Digits) {
+		return fmt.Errorf("-list est incompatible avec -lucas, -mod, -sum et -digits")
+	}
+	if c.Ratio && (c.Lucas || c.Mod != "" || c.Sum || c.Digits || c.List) {
+		return fmt.Errorf("-ratio est incompatible avec -lucas, -mod, -sum, -digits et -list")
+	}
+	if c.Quiet && (c.Histogram || c.Binary || c.Base != 0 || c.Clipboard) {
+		return fmt.Errorf("-quiet est incompatible avec -digit-histogram, -binary, -base et -clipboard")
+	}
+	if c.Sum && c.Lucas {
+		return fmt.Errorf("-sum est incompatible avec -lucas")
+	}
+	return nil
+}
+
+// standaloneModes retourne les options fournies parmi celles qui remplacent
+// entièrement le calcul de F(n) et prennent leur propre argument.
+func (c Configuration) standaloneModes() []string {
+	var modes []string
+	if c.Zeckendorf != "" {
+		modes = append(modes, "-zeckendorf")
+	}
+	if c.Gcd != "" {
+		modes = append(modes, "-gcd")
+	}
+	if c.FibCode != "" {
+		modes = append(modes, "-fibcode")
+	}
+	if c.FibDecode != "" {
+		modes = append(modes, "-fibdecode")
+	}
+	return modes
+}
+
+// withDeadline retourne un contexte dérivé de parent, borné par l'échéance
+// -deadline si elle est définie, sinon par la durée -timeout.
+func (c Configuration) withDeadline(parent context.Context) (context.Context, context.CancelFunc) {
+	if !c.Deadline.IsZero() {
+		return context.WithDeadline(parent, c.Deadline)
+	}
+	return context.WithTimeout(parent, c.Timeout)
+}
+
+// applyMaxProcs fixe GOMAXPROCS explicitement pour exploiter tous les cœurs
+// disponibles, ou seulement maxProcs d'entre eux sur une machine partagée.
+func applyMaxProcs(maxProcs int) {
+	procs := runtime.NumCPU()
+	if maxProcs > 0 {
+		procs = maxProcs
+	}
+	runtime.GOMAXPROCS(procs)
+}
+
+// formatInBase retourne la représentation de v demandée par -base ou -binary,
+// ou une chaîne vide si aucune des deux options n'est active. -base prime sur
+// -binary et n'ajoute pas de préfixe.
+func formatInBase(v *big.Int, c Configuration) string {
+	switch {
+	case c.Base != 0:
+		return v.Text(c.Base)
+	case c.Binary:
+		return "0b" + v.Text(2)
+	default:
+		return ""
+	}
+}
+
+// sequenceIndex traduit l'indice n saisi selon la convention offset en indice
+// interne (indexation à partir de 0, F(0)=0, F(1)=1).
+//
+//   - offset 0 : la suite commence à n=0 par 0, 1, 1, 2, … ; l'indice est inchangé.
+//   - offset 1 : la suite commence à n=1 par 1, 1, 2, 3, … ; comme F(1)=F(2)=1
+//     dans la convention interne, les valeurs coïncident pour n ≥ 1 et seul
+//     n=0, qui n'appartient pas à la suite, est rejeté.
+func sequenceIndex(n, offset int) (int, error) {
+	switch offset {
+	case 0:
+		return n, nil
+	case 1:
+		if n < 1 {
+			return 0, fmt.Errorf("avec -offset 1, n doit être supérieur ou égal à 1 (reçu %d)", n)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("offset inconnu : %d (attendu : 0 ou 1)", offset)
 	}
 }
 
@@ -59,11 +340,16 @@ func (m *Metrics) AddCalculations(n int64) {
 }
 
 // FibCalculator encapsule le calcul du n-ième nombre de Fibonacci.
-type FibCalculator struct{}
+type FibCalculator struct {
+	pair func(n int) (*big.Int, *big.Int, error) // Calcul du couple (F(n), F(n+1))
+}
 
 // NewFibCalculator retourne une nouvelle instance de FibCalculator.
 func NewFibCalculator() *FibCalculator {
-	return &FibCalculator{}
+	return &FibCalculator{pair: func(n int) (*big.Int, *big.Int, error) {
+		a, b := fibDoublingPair(n)
+		return a, b, nil
+	}}
 }
 
 // Calculate retourne F(n) pour n ≥ 0.
@@ -78,72 +364,155 @@ func (fc *FibCalculator) Calculate(n int) (*big.Int, error) {
 	if n == 1 {
 		return big.NewInt(1), nil
 	}
-	return fibDoublingParallel(n)
+	a, _, err := fc.pair(n)
+	return a, err
+}
+
+// CalculateLucas retourne le nombre de Lucas L(n) pour n ≥ 0 (L(0)=2, L(1)=1).
+// L'identité L(n) = F(n-1) + F(n+1) = 2·F(n+1) - F(n) permet de réutiliser le
+// couple (F(n), F(n+1)) produit par l'algorithme du doublement.
+func (fc *FibCalculator) CalculateLucas(n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("n doit être non négatif")
+	}
+	fn, fn1, err := fc.pair(n)
+	if err != nil {
+		return nil, err
+	}
+	lucas := new(big.Int).Lsh(fn1, 1)
+	return lucas.Sub(lucas, fn), nil
+}
+
+// PrefixSum retourne la somme des n premiers nombres de Fibonacci,
+// F(0) + F(1) + … + F(n-1), grâce à l'identité Σ F(i) = F(n+1) - 1 (i < n).
+// Le calcul est en O(log n) au lieu d'une addition par terme.
+func (fc *FibCalculator) PrefixSum(n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("n doit être non négatif")
+	}
+	_, fn1, err := fc.pair(n)
+	if err != nil {
+		return nil, err
+	}
+	return fn1.Sub(fn1, big.NewInt(1)), nil
 }
 
-// fibDoublingParallel calcule F(n) en utilisant l'algorithme itératif du doublement
-// avec parallélisation des opérations coûteuses. L'algorithme parcourt les bits de n
-// du plus significatif au moins significatif et, pour chaque itération, lance des
-// goroutines pour calculer simultanément les multiplications.
-func fibDoublingParallel(n int) (*big.Int, error) {
+// fibDoublingPair retourne le couple (F(n), F(n+1)) calculé par l'algorithme du
+// doublement parallélisé. L'algorithme parcourt les bits de n du plus significatif
+// au moins significatif et, pour chaque itération, lance des goroutines pour
+// calculer simultanément les multiplications. Les deux valeurs sont disponibles
+// à l'issue de la même boucle, ce qui évite un second calcul lorsque F(n+1) est
+// également nécessaire.
+func fibDoublingPair(n int) (*big.Int, *big.Int) {
 	// Initialisation : a = F(0) = 0, b = F(1) = 1
 	a := big.NewInt(0)
 	b := big.NewInt(1)
 
-	// Détermination du bit le plus significatif de n
-	highest := 0
-	for i := 31; i >= 0; i-- {
-		if n&(1<<i) != 0 {
-			highest = i
-			break
-		}
+	// Parcours des bits de n, du plus significatif au moins significatif
+	for i := highestBit(n); i >= 0; i-- {
+		doublingStep(a, b, n&(1<<uint(i)) != 0)
 	}
+	return a, b
+}
+
+// highestBit retourne la position du bit le plus significatif de n (0 pour n ≤ 1).
+func highestBit(n int) int {
+	return max(bits.Len(uint(n))-1, 0)
+}
+
+// doublingStep fait passer (a, b) = (F(k), F(k+1)) à (F(2k), F(2k+1)), ou à
+// (F(2k+1), F(2k+2)) si bit est vrai. a et b sont modifiés sur place.
+func doublingStep(a, b *big.Int, bit bool) {
+	// Calcul de deuxB = 2 * b
+	twoB := new(big.Int).Lsh(b, 1)
+	// Calcul de temp = 2*b - a
+	temp := new(big.Int).Sub(twoB, a)
+
+	// Création de canaux pour récupérer les résultats des multiplications
+	cChan := make(chan *big.Int, 1)
+	t1Chan := make(chan *big.Int, 1)
+	t2Chan := make(chan *big.Int, 1)
+
+	// Calcul de c = a * (2*b - a) en parallèle
+	go func(a, temp *big.Int) {
+		cChan <- new(big.Int).Mul(a, temp)
+	}(new(big.Int).Set(a), temp)
+
+	// Calcul de t1 = a * a en parallèle
+	go func(a *big.Int) {
+		t1Chan <- new(big.Int).Mul(a, a)
+	}(new(big.Int).Set(a))
+
+	// Calcul de t2 = b * b en parallèle
+	go func(b *big.Int) {
+		t2Chan <- new(big.Int).Mul(b, b)
+	}(new(big.Int).Set(b))
+
+	// Récupération des résultats
+	c := <-cChan
+	t1 := <-t1Chan
+	t2 := <-t2Chan
+
+	// Calcul de d = a*a + b*b
+	d := new(big.Int).Add(t1, t2)
+
+	// Mise à jour de (a, b) selon le bit courant de n
+	if bit {
+		a.Set(d)
+		b.Add(c, d)
+	} else {
+		a.Set(c)
+		b.Set(d)
+	}
+}
+
+// parseModulus interprète la chaîne s comme un module strictement positif.
+// Une chaîne vide signifie l'absence de module (nil).
+func parseModulus(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m, ok := new(big.Int).SetString(s, 10)
+	if !ok || m.Sign() <= 0 {
+		return nil, fmt.Errorf("module invalide : %q (entier strictement positif attendu)", s)
+	}
+	return m, nil
+}
+
+// fibDoublingPairMod retourne (F(n) mod m, F(n+1) mod m) par l'algorithme du
+// doublement, en réduisant chaque valeur intermédiaire modulo m. Les nombres
+// restent ainsi de la taille de m, ce qui rend le calcul quasi instantané même
+// pour n de l'ordre de 10^18 ; la parallélisation n'apporte alors rien.
+func fibDoublingPairMod(n int, m *big.Int) (*big.Int, *big.Int) {
+	a := new(big.Int).Mod(big.NewInt(0), m)
+	b := new(big.Int).Mod(big.NewInt(1), m)
+	c := new(big.Int)
+	d := new(big.Int)
+	t := new(big.Int)
+
+	for i := bits.Len(uint(n)) - 1; i >= 0; i-- {
+		// c = a * (2*b - a) mod m
+		t.Lsh(b, 1)
+		t.Sub(t, a)
+		c.Mul(a, t)
+		c.Mod(c, m)
+
+		// d = a*a + b*b mod m
+		d.Mul(a, a)
+		t.Mul(b, b)
+		d.Add(d, t)
+		d.Mod(d, m)
 
-	// Parcours des bits de n, du plus significatif au moins significatif
-	for i := highest; i >= 0; i-- {
-		// Calcul de deuxB = 2 * b
-		twoB := new(big.Int).Lsh(b, 1)
-		// Calcul de temp = 2*b - a
-		temp := new(big.Int).Sub(twoB, a)
-
-		// Création de canaux pour récupérer les résultats des multiplications
-		cChan := make(chan *big.Int, 1)
-		t1Chan := make(chan *big.Int, 1)
-		t2Chan := make(chan *big.Int, 1)
-
-		// Calcul de c = a * (2*b - a) en parallèle
-		go func(a, temp *big.Int) {
-			cChan <- new(big.Int).Mul(a, temp)
-		}(new(big.Int).Set(a), temp)
-
-		// Calcul de t1 = a * a en parallèle
-		go func(a *big.Int) {
-			t1Chan <- new(big.Int).Mul(a, a)
-		}(new(big.Int).Set(a))
-
-		// Calcul de t2 = b * b en parallèle
-		go func(b *big.Int) {
-			t2Chan <- new(big.Int).Mul(b, b)
-		}(new(big.Int).Set(b))
-
-		// Récupération des résultats
-		c := <-cChan
-		t1 := <-t1Chan
-		t2 := <-t2Chan
-
-		// Calcul de d = a*a + b*b
-		d := new(big.Int).Add(t1, t2)
-
-		// Mise à jour de (a, b) selon le bit courant de n
 		if n&(1<<uint(i)) != 0 {
 			a.Set(d)
 			b.Add(c, d)
+			b.Mod(b, m)
 		} else {
 			a.Set(c)
 			b.Set(d)
 		}
 	}
-	return a, nil
+	return a, b
 }
 
 // toSuperscript convertit une chaîne composée de chiffres (et éventuellement le signe '-')
@@ -174,60 +543,393 @@ func toSuperscript(s string) string {
 }
 
 // formatBigIntSup formate un grand entier en notation scientifique avec l'exposant
-// rendu en caractères Unicode superscript. Par exemple : "3.54224×10²⁰".
-func formatBigIntSup(n *big.Int) string {
+// rendu en caractères Unicode superscript. La mantisse est tronquée (et non
+// arrondie) à precision chiffres significatifs. Par exemple, avec precision=6 :
+// "3.54224×10²⁰".
+func formatBigIntSup(n *big.Int, precision int) string {
 	s := n.String()
 	if len(s) <= 1 {
 		return s
 	}
-	// Choix du nombre de chiffres significatifs (ici 6 chiffres au total)
-	var significand string
-	if len(s) > 6 {
-		significand = s[:1] + "." + s[1:6]
-	} else {
-		significand = s[:1] + "." + s[1:]
+	significand := s[:min(len(s), precision)]
+	if len(significand) > 1 {
+		significand = significand[:1] + "." + significand[1:]
 	}
 	exponent := len(s) - 1
 	supExp := toSuperscript(fmt.Sprintf("%d", exponent))
 	return fmt.Sprintf("%s×10%s", significand, supExp)
 }
 
-func main() {
-	// Configuration explicite pour exploiter tous les cœurs disponibles
-	runtime.GOMAXPROCS(runtime.NumCPU())
+// maxGroupedDigits est la taille au-delà de laquelle -group est ignoré : le
+// résultat reste alors affiché en notation scientifique.
+const maxGroupedDigits = 10000
+
+// groupDigits insère sep entre chaque groupe de trois chiffres de la
+// représentation décimale s, en partant de la droite.
+func groupDigits(s, sep string) string {
+	if len(s) <= 3 || sep == "" {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + len(s)/3*len(sep))
+	first := len(s) % 3
+	if first == 0 {
+		first = 3
+	}
+	b.WriteString(s[:first])
+	for i := first; i < len(s); i += 3 {
+		b.WriteString(sep)
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+// localeSeparators associe à chaque langue acceptée par -lang son séparateur
+// de milliers. Le français utilise une espace insécable, qui empêche le
+// terminal de couper le nombre en fin de ligne.
+var localeSeparators = map[string]string{
+	"fr": "\u00a0",
+	"de": ".",
+	"en": ",",
+}
+
+// formatNumberForLocale groupe les chiffres de s selon l'usage de la langue
+// lang. s est retourné tel quel si la langue est inconnue.
+func formatNumberForLocale(s, lang string) string {
+	sep, ok := localeSeparators[lang]
+	if !ok {
+		return s
+	}
+	return groupDigits(s, sep)
+}
+
+// groupResult retourne v groupé par milliers selon -lang ou -group-sep. ok
+// est faux si -group est absent ou si v dépasse maxGroupedDigits chiffres :
+// la taille est mesurée sur la valeur affichée, qui peut compter un chiffre
+// de plus que F(n) avec -lucas ou -sum.
+func groupResult(v *big.Int, config Configuration) (grouped string, ok bool) {
+	if !config.Group {
+		return "", false
+	}
+	s := v.String()
+	if len(s) > maxGroupedDigits {
+		return "", false
+	}
+	if config.Lang != "" {
+		return formatNumberForLocale(s, config.Lang), true
+	}
+	return groupDigits(s, config.GroupSep), true
+}
+
+// truncateDigits retourne s limité à ses k premiers et k derniers chiffres,
+// séparés par le nombre de chiffres omis. s est retourné entier si k vaut 0 ou
+// si la troncature ne retirerait rien.
+func truncateDigits(s string, k int) string {
+	if k <= 0 || len(s) <= 2*k {
+		return s
+	}
+	return fmt.Sprintf("%s…(%d chiffres omis)…%s", s[:k], len(s)-2*k, s[len(s)-k:])
+}
 
+func main() {
 	// Initialisation de la configuration et des métriques.
-	config := DefaultConfig()
+	config, err := parseFlags()
+	if err != nil {
+		log.Fatalf("Configuration invalide : %v", err)
+	}
+
+	applyMaxProcs(config.MaxProcs)
+	if config.Version {
+		info := buildVersionInfo()
+		fmt.Printf("Version : %s\nCommit  : %s\nDate    : %s\nGo      : %s\n", info.Version, info.Commit, info.BuildDate, info.Go)
+		return
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Configuration invalide : %v", err)
+	}
+	// Profilage : finalisé à la sortie de main, ou par fatalf en cas d'erreur.
+	activeProfiler, err = startProfiling(config.CPUProfile, config.MemProfile, config.Trace)
+	if err != nil {
+		log.Fatalf("Configuration invalide : %v", err)
+	}
+	defer activeProfiler.Stop()
+	format, err := resolveFormat(config)
+	if err != nil {
+		fatalf("Configuration invalide : %v", err)
+	}
+	// Auto-test : chaque chemin de calcul est comparé à l'oracle.
+	if config.SelfTest {
+		selfTestMode(os.Stdout, selfTestCases(), selfTestIndices)
+		return
+	}
+	// Représentation de Zeckendorf : l'argument est l'entier à décomposer, pas un indice.
+	if config.Zeckendorf != "" {
+		x, err := parsePositiveInt(config.Zeckendorf)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		indices, err := Zeckendorf(x)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		fmt.Println(formatZeckendorf(x, indices))
+		return
+	}
+	// Identité du pgcd : les deux membres sont calculés séparément puis comparés.
+	if config.Gcd != "" {
+		m, n, err := parseIndexPair(config.Gcd)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		ctx, cancel := config.withDeadline(context.Background())
+		defer cancel()
+		check, _ := runUntilDone(ctx, func() (GcdCheck, error) { return verifyGcdIdentity(m, n), nil })
+		if ctx.Err() != nil {
+			fatalf("Délai d'exécution dépassé : %v", ctx.Err())
+		}
+		fmt.Printf("pgcd(F(%d), F(%d)) = %s\n", m, n, formatBigIntSup(check.GcdOfFib, config.SciPrecision))
+		fmt.Printf("F(pgcd(%d, %d)) = F(%d) = %s\n", m, n, check.G, formatBigIntSup(check.FibOfGcd, config.SciPrecision))
+		if !check.Holds() {
+			fatalf("Identité non vérifiée pour (%d, %d)", m, n)
+		}
+		fmt.Println("Identité vérifiée")
+		return
+	}
+	// Codage de Fibonacci : dans un sens ou dans l'autre, sans calcul de F(n).
+	if config.FibCode != "" {
+		x, err := parsePositiveInt(config.FibCode)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		code, err := FibonacciEncode(x)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		fmt.Println(code)
+		return
+	}
+	if config.FibDecode != "" {
+		x, err := FibonacciDecode(config.FibDecode)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		fmt.Println(x)
+		return
+	}
+	// Mode entrée standard : un résultat par indice lu, dans l'ordre.
+	if config.Stdin {
+		calc, err := NewCalculation(config)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		if err := runStdin(os.Stdin, os.Stdout, calc, config.Offset, format, config.JSONPretty); err != nil {
+			fatalf("Erreur lors de la lecture de l'entrée standard : %v", err)
+		}
+		return
+	}
+
+	// Mode lot : calcule les indices du fichier, éventuellement en continu.
+	// Chaque passe sur le fichier est bornée par -timeout (ou -deadline).
+	if config.InputFile != "" {
+		calc, err := NewCalculation(config)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		runBatch := func() error {
+			ctx, cancel := config.withDeadline(ctx)
+			defer cancel()
+			return runInputFile(ctx, os.Stdout, config.InputFile, calc, config.Offset, config.SciPrecision)
+		}
+		if !config.Watch {
+			if err := runBatch(); err != nil {
+				fatalf("Erreur lors du calcul par lot : %v", err)
+			}
+			return
+		}
+		watchInputFile(ctx, config.InputFile, watchInterval, watchDebounce, func() {
+			if err := runBatch(); err != nil {
+				log.Printf("Erreur lors du calcul par lot : %v", err)
+			}
+		})
+		return
+	}
+
+	index, err := sequenceIndex(config.M, config.Offset)
+	if err != nil {
+		fatalf("Configuration invalide : %v", err)
+	}
+
+	// Mode nombre de chiffres : affiche le nombre de chiffres de F(n) et s'arrête.
+	if config.Digits {
+		if index < 0 {
+			fatalf("Configuration invalide : n doit être non négatif")
+		}
+		fmt.Println(DigitCount(uint64(index)))
+		return
+	}
+
+	// Mode liste : affiche la suite à partir du premier terme de la convention
+	// d'indexation (F(0) ou F(1)) jusqu'à F(n), et s'arrête.
+	if config.List {
+		if index < 0 {
+			fatalf("Configuration invalide : n doit être non négatif")
+		}
+		ctx, cancel := config.withDeadline(context.Background())
+		defer cancel()
+		out := bufio.NewWriter(os.Stdout)
+		for v := range fibSequence(ctx, config.Offset, index) {
+			fmt.Fprintln(out, v)
+		}
+		if err := out.Flush(); err != nil {
+			fatalf("Erreur lors de l'écriture de la suite : %v", err)
+		}
+		if ctx.Err() != nil {
+			fatalf("Délai d'exécution dépassé : %v", ctx.Err())
+		}
+		return
+	}
+
+	// Mode convergent : affiche F(n)/F(n-1) et son développement décimal.
+	if config.Ratio {
+		ctx, cancel := config.withDeadline(context.Background())
+		defer cancel()
+		var num, den *big.Int
+		decimal, err := runUntilDone(ctx, func() (string, error) {
+			n, d, decimal, err := fibRatio(index, config.RatioDigits)
+			num, den = n, d
+			return decimal, err
+		})
+		if ctx.Err() != nil {
+			fatalf("Délai d'exécution dépassé : %v", ctx.Err())
+		}
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		fmt.Printf("F(%d)/F(%d) : %s\n", config.M, config.M-1, formatRatio(index, num, den, config.SciPrecision))
+		fmt.Printf("            ≈ %s\n", decimal)
+		return
+	}
+
+	// Mode nombre d'or : affiche φ et s'arrête.
+	if config.PhiDigits != 0 {
+		ctx, cancel := config.withDeadline(context.Background())
+		defer cancel()
+		phi, err := runUntilDone(ctx, func() (string, error) { return computePhi(config.PhiDigits) })
+		if ctx.Err() != nil {
+			fatalf("Délai d'exécution dépassé : %v", ctx.Err())
+		}
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		fmt.Printf("φ (%d décimales) : %s\n", config.PhiDigits, phi)
+		return
+	}
+
+	// Mode période de Pisano : affiche π(m) et s'arrête.
+	if config.Pisano != 0 {
+		ctx, cancel := config.withDeadline(context.Background())
+		defer cancel()
+		period, err := PisanoPeriod(ctx, config.Pisano)
+		if err != nil {
+			fatalf("Erreur lors du calcul de la période de Pisano : %v", err)
+		}
+		fmt.Printf("π(%d) = %d\n", config.Pisano, period)
+		return
+	}
+
+	// Garde-fou mémoire ; un résultat modulaire reste de la taille du module.
+	if config.MaxMemory != "" && config.Mod == "" {
+		budget, err := parseByteSize(config.MaxMemory)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		if err := checkMemoryBudget(index, budget); err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+	}
+
+	// Mode banc d'essai : répète le calcul sans afficher le résultat. Ctrl-C
+	// arrête la série après l'exécution en cours et affiche les mesures obtenues.
+	if config.Repeat > 0 {
+		calc, err := NewCalculation(config)
+		if err != nil {
+			fatalf("Configuration invalide : %v", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		ctx, cancel := config.withDeadline(ctx)
+		defer cancel()
+
+		fmt.Printf("Banc d'essai de %s(%d), %d exécutions\n", calc.Label, config.M, config.Repeat)
+		durations, err := benchmarkRuns(ctx, config.Repeat, func() error {
+			_, err := calc.Compute(index)
+			return err
+		})
+		if len(durations) > 0 {
+			printDurationStats(os.Stdout, summarizeDurations(durations), config.Repeat)
+		}
+		if err != nil {
+			fatalf("Banc d'essai interrompu : %v", err)
+		}
+		return
+	}
+
 	metrics := NewMetrics()
 
 	// Création d'un contexte avec timeout pour limiter la durée d'exécution.
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	ctx, cancel := config.withDeadline(context.Background())
 	defer cancel()
 
-	// Calcul de Fibonacci(config.M)
-	fc := NewFibCalculator()
+	// Calcul de Fibonacci(config.M), ou de Lucas(config.M) si demandé
+	calc, err := NewCalculation(config)
+	if err != nil {
+		fatalf("Configuration invalide : %v", err)
+	}
+	label, modulus := calc.Label, calc.Modulus
 	resultChan := make(chan *big.Int, 1)
 	errorChan := make(chan error, 1)
 
+	var fromCache bool
 	go func() {
-		fib, err := fc.Calculate(config.M)
+		fib, cached, err := calc.Run(index)
 		if err != nil {
 			errorChan <- err
 			return
 		}
+		fromCache = cached
 		resultChan <- fib
 	}()
 
 	var fibResult *big.Int
 	select {
 	case <-ctx.Done():
-		log.Fatalf("Délai d'exécution dépassé : %v", ctx.Err())
+		fatalf("Délai d'exécution dépassé : %v", ctx.Err())
 	case err := <-errorChan:
-		log.Fatalf("Erreur lors du calcul de Fibonacci : %v", err)
+		fatalf("Erreur lors du calcul de %s : %v", label, err)
 	case fibResult = <-resultChan:
 		// Calcul terminé.
 	}
 
+	// Mode silencieux : seul le résultat brut est écrit, sur la sortie standard
+	// ou dans le fichier -out ; les erreurs passent par log (sortie d'erreur).
+	if config.Quiet {
+		record := resultRecord{N: config.M, Value: fibResult, Pretty: config.JSONPretty}
+		if config.OutputFile != "" {
+			err = writeResultFile(config.OutputFile, format, record, isCompressedOutput(config.OutputFile, config.Compress))
+		} else {
+			out := bufio.NewWriter(os.Stdout)
+			if err = writeResult(out, format, record); err == nil {
+				err = out.Flush()
+			}
+		}
+		if err != nil {
+			fatalf("Erreur lors de l'écriture du résultat : %v", err)
+		}
+		return
+	}
+
 	// Comptabilisation du calcul effectué.
 	metrics.AddCalculations(1)
 	metrics.EndTime = time.Now()
@@ -240,16 +942,79 @@ func main() {
 	// Affichage des résultats et des métriques.
 	fmt.Printf("\nConfiguration :\n")
 	fmt.Printf("  Valeur de M             : %d\n", config.M)
-	fmt.Printf("  Timeout                 : %v\n", config.Timeout)
+	fmt.Printf("  Processeurs utilisés    : %d\n", runtime.GOMAXPROCS(0))
+	if config.Deadline.IsZero() {
+		fmt.Printf("  Timeout                 : %v\n", config.Timeout)
+	} else {
+		fmt.Printf("  Échéance                : %s\n", config.Deadline.Format(time.RFC3339))
+	}
 	fmt.Printf("  Nombre de cœurs utilisés: %d\n", runtime.NumCPU())
 
 	fmt.Printf("\nPerformance :\n")
-	fmt.Printf("  Temps total d'exécution : %v\n", duration)
+	fmt.Printf("  Temps total d'exécution : %s\n", formatDuration(duration))
 	fmt.Printf("  Nombre de calculs       : %d\n", metrics.TotalCalculations)
-	fmt.Printf("  Temps moyen par calcul  : %v\n", avgTime)
+	fmt.Printf("  Temps moyen par calcul  : %s\n", formatDuration(avgTime))
+	if fromCache {
+		fmt.Printf("  Résultat lu depuis le cache %s\n", config.CacheDir)
+	}
 
 	// Affichage du résultat en notation scientifique avec l'exposant en superscript.
-	formattedResult := formatBigIntSup(fibResult)
+	// Un résidu modulaire, de la taille du module, est affiché en entier.
+	formattedResult := formatBigIntSup(fibResult, config.SciPrecision)
+	modSuffix := ""
+	if modulus != nil {
+		formattedResult = fibResult.String()
+		modSuffix = " mod " + modulus.String()
+	}
+	if grouped, ok := groupResult(fibResult, config); ok {
+		formattedResult = grouped
+	}
 	fmt.Printf("\nRésultat :\n")
-	fmt.Printf("  Fibonacci(%d) : %s\n", config.M, formattedResult)
+	fmt.Printf("  %s(%d)%s : %s\n", label, config.M, modSuffix, formattedResult)
+	if config.OutputDigits >= 0 {
+		fmt.Printf("  Décimal : %s\n", truncateDigits(fibResult.String(), config.OutputDigits))
+	}
+	if config.Clipboard {
+		if err := copyToClipboard(clipboard, fibResult, config.OutputDigits); err != nil {
+			log.Printf("Presse-papiers : %v", err)
+		} else {
+			fmt.Printf("  Copié dans le presse-papiers\n")
+		}
+	}
+	if text := formatInBase(fibResult, config); text != "" {
+		base := config.Base
+		if base == 0 {
+			base = 2
+		}
+		fmt.Printf("  En base %d : %s\n", base, text)
+	}
+
+	record := resultRecord{N: config.M, Value: fibResult, Pretty: config.JSONPretty}
+
+	// Histogramme des chiffres décimaux du résultat.
+	if config.Histogram {
+		record.DigitHistogram, err = computeDigitHistogram(fibResult)
+		if err != nil {
+			fatalf("Erreur lors du calcul de l'histogramme : %v", err)
+		}
+		printDigitHistogram(os.Stdout, record.DigitHistogram)
+	}
+
+	// Écriture du résultat complet dans le fichier demandé.
+	if config.OutputFile != "" {
+		compress := isCompressedOutput(config.OutputFile, config.Compress)
+		if err := writeResultFile(config.OutputFile, format, record, compress); err != nil {
+			fatalf("Erreur lors de l'écriture du résultat : %v", err)
+		}
+		if compress {
+			format += ", gzip"
+		}
+		fmt.Printf("  Résultat écrit dans     : %s (%s)\n", config.OutputFile, format)
+	} else if format == FormatBits {
+		// Sans fichier de sortie, le développement binaire est écrit sur la sortie standard.
+		fmt.Printf("  Représentation binaire  : ")
+		if err := writeResult(os.Stdout, format, record); err != nil {
+			fatalf("Erreur lors de l'écriture du résultat : %v", err)
+		}
+	}
 }
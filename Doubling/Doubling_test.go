@@ -0,0 +1,309 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"math/big"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestValidateStandaloneModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(*Configuration)
+		wantErr string // Fragment attendu du message, vide si la configuration est valide
+	}{
+		{"zeckendorf seul", func(c *Configuration) { c.Zeckendorf = "100" }, ""},
+		{"zeckendorf et -n", func(c *Configuration) { c.Zeckendorf = "100"; c.IndexSet = true }, "-zeckendorf est incompatible"},
+		{"zeckendorf et -lucas", func(c *Configuration) { c.Zeckendorf = "100"; c.Lucas = true }, "-zeckendorf est incompatible"},
+		{"zeckendorf et -mod", func(c *Configuration) { c.Zeckendorf = "100"; c.Mod = "7" }, "-zeckendorf est incompatible"},
+		{"zeckendorf et -stdin", func(c *Configuration) { c.Zeckendorf = "100"; c.Stdin = true }, "-zeckendorf est incompatible"},
+		{"gcd seul", func(c *Configuration) { c.Gcd = "12,18" }, ""},
+		{"gcd et -n", func(c *Configuration) { c.Gcd = "12,18"; c.IndexSet = true }, "-gcd est incompatible"},
+		{"gcd et -lucas", func(c *Configuration) { c.Gcd = "12,18"; c.Lucas = true }, "-gcd est incompatible"},
+		{"gcd et -mod", func(c *Configuration) { c.Gcd = "12,18"; c.Mod = "7" }, "-gcd est incompatible"},
+		{"zeckendorf et gcd", func(c *Configuration) { c.Zeckendorf = "100"; c.Gcd = "12,18" }, "-zeckendorf et -gcd sont incompatibles"},
+		{"fibcode seul", func(c *Configuration) { c.FibCode = "11" }, ""},
+		{"fibdecode seul", func(c *Configuration) { c.FibDecode = "001011" }, ""},
+		{"fibcode et -n", func(c *Configuration) { c.FibCode = "11"; c.IndexSet = true }, "-fibcode est incompatible"},
+		{"fibcode et -mod", func(c *Configuration) { c.FibCode = "11"; c.Mod = "7" }, "-fibcode est incompatible"},
+		{"fibdecode et -lucas", func(c *Configuration) { c.FibDecode = "001011"; c.Lucas = true }, "-fibdecode est incompatible"},
+		{"fibcode et fibdecode", func(c *Configuration) { c.FibCode = "11"; c.FibDecode = "001011" }, "-fibcode et -fibdecode sont incompatibles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.edit(&config)
+			err := config.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Validate() = %v, attendu nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("Validate() = %v, attendu une erreur contenant %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// wrongSelfTestCases retourne un chemin de calcul délibérément faux pour n = 10.
+func wrongSelfTestCases() []selfTestCase {
+	fc := NewFibCalculator()
+	return []selfTestCase{{
+		Name: "Calculateur faux",
+		Compute: func(n int) (*big.Int, error) {
+			v, err := fc.Calculate(n)
+			if n == 10 {
+				v.Add(v, big.NewInt(1))
+			}
+			return v, err
+		},
+		Expected: func(o oracleValues) *big.Int { return o.F },
+	}}
+}
+
+func TestSelfTestPasses(t *testing.T) {
+	var out strings.Builder
+	if failures := runSelfTest(&out, selfTestCases(), []int{0, 1, 2, 10, 93, 1000, 10000}); failures != 0 {
+		t.Fatalf("%d écart(s) :\n%s", failures, out.String())
+	}
+	if strings.Contains(out.String(), "ÉCHEC") {
+		t.Errorf("résumé signalant un échec :\n%s", out.String())
+	}
+}
+
+func TestSelfTestReportsDisagreement(t *testing.T) {
+	var out strings.Builder
+	if failures := runSelfTest(&out, wrongSelfTestCases(), selfTestIndices); failures != 1 {
+		t.Fatalf("%d écart(s), attendu 1 :\n%s", failures, out.String())
+	}
+	for _, want := range []string{"ÉCHEC Calculateur faux(10) : obtenu 56, attendu 55", "6/7 ÉCHEC"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("résumé sans %q :\n%s", want, out.String())
+		}
+	}
+}
+
+// TestSelfTestModeExitsNonZero relance le binaire de test dans un processus
+// fils, qui exécute selfTestMode avec le calculateur faux, et vérifie son code
+// de sortie.
+func TestSelfTestModeExitsNonZero(t *testing.T) {
+	if os.Getenv("FIB_SELFTEST_CHILD") == "1" {
+		selfTestMode(os.Stdout, wrongSelfTestCases(), selfTestIndices)
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSelfTestModeExitsNonZero$")
+	cmd.Env = append(os.Environ(), "FIB_SELFTEST_CHILD=1")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() == 0 {
+		t.Fatalf("processus fils : %v, attendu un code de sortie non nul\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Auto-test en échec : 1 écart(s)") {
+		t.Errorf("sortie sans le message d'échec :\n%s", out)
+	}
+}
+
+// TestVersionReturnsBeforeCalculation vérifie que -version s'arrête avant la
+// validation et le calcul : la configuration passée au processus fils serait
+// rejetée par Validate et F(10¹²) ne tiendrait pas dans -timeout.
+func TestVersionReturnsBeforeCalculation(t *testing.T) {
+	if os.Getenv("FIB_VERSION_CHILD") == "1" {
+		os.Args = []string{"Doubling", "-version", "-n", "1e12", "-timeout", "1ms", "-zeckendorf", "-lucas"}
+		main()
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestVersionReturnsBeforeCalculation$")
+	cmd.Env = append(os.Environ(), "FIB_VERSION_CHILD=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("processus fils : %v, attendu un code de sortie nul\n%s", err, out)
+	}
+	for _, line := range []string{"Version : ", "Commit  : ", "Date    : ", "Go      : go"} {
+		if !strings.Contains(string(out), line) {
+			t.Errorf("sortie sans %q :\n%s", line, out)
+		}
+	}
+	if strings.Contains(string(out), "Configuration invalide") {
+		t.Errorf("la configuration a été validée malgré -version :\n%s", out)
+	}
+}
+
+func TestFormatNumberForLocale(t *testing.T) {
+	f30, _ := fibDoublingPair(30)
+	f40, _ := fibDoublingPair(40)
+	tests := []struct {
+		v    *big.Int
+		lang string
+		want string
+	}{
+		{f30, "fr", "832\u00a0040"},
+		{f30, "de", "832.040"},
+		{f30, "en", "832,040"},
+		{f40, "de", "102.334.155"},
+		{f30, "it", "832040"}, // Langue inconnue : chiffres inchangés
+	}
+	for _, tt := range tests {
+		if got := formatNumberForLocale(tt.v.String(), tt.lang); got != tt.want {
+			t.Errorf("formatNumberForLocale(%s, %q) = %q ; attendu %q", tt.v, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestGroupResult(t *testing.T) {
+	f30, _ := fibDoublingPair(30)
+	grouping := DefaultConfig()
+	grouping.Group = true
+
+	if got, ok := groupResult(f30, grouping); !ok || got != "832,040" {
+		t.Errorf("groupResult(F(30)) = %q, %v ; attendu \"832,040\"", got, ok)
+	}
+	if got, ok := groupResult(f30, DefaultConfig()); ok {
+		t.Errorf("sans -group : %q groupé ; attendu inchangé", got)
+	}
+
+	// 10^maxGroupedDigits compte un chiffre de trop, comme L(n) ou la somme
+	// lorsque F(n) en compte exactement maxGroupedDigits.
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(maxGroupedDigits-1), nil)
+	if _, ok := groupResult(limit, grouping); !ok {
+		t.Errorf("%d chiffres : non groupé ; attendu groupé", maxGroupedDigits)
+	}
+	over := new(big.Int).Mul(limit, big.NewInt(10))
+	if got, ok := groupResult(over, grouping); ok {
+		t.Errorf("%d chiffres : groupé (%d caractères) ; attendu inchangé", maxGroupedDigits+1, len(got))
+	}
+}
+
+func TestTruncateDigits(t *testing.T) {
+	f100, _ := fibDoublingPair(100) // 354224848179261915075, 21 chiffres
+	tests := []struct {
+		k    int
+		want string
+	}{
+		{5, "35422…(11 chiffres omis)…15075"},
+		{0, "354224848179261915075"},
+		{10, "3542248481…(1 chiffres omis)…9261915075"},
+		{11, "354224848179261915075"}, // Rien à retirer
+	}
+	for _, tt := range tests {
+		if got := truncateDigits(f100.String(), tt.k); got != tt.want {
+			t.Errorf("truncateDigits(F(100), %d) = %q ; attendu %q", tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBigIntSup(t *testing.T) {
+	f100, _ := fibDoublingPair(100) // 354224848179261915075 = 3.54…×10²⁰
+	got := formatBigIntSup(f100, 6)
+	mantissa, exponent, found := strings.Cut(got, "×10")
+	if !found || exponent != "²⁰" {
+		t.Fatalf("formatBigIntSup(F(100), 6) = %q ; attendu l'exposant ²⁰", got)
+	}
+	if digits := strings.Replace(mantissa, ".", "", 1); len(digits) != 6 || mantissa != "3.54224" {
+		t.Errorf("mantisse %q ; attendu 6 chiffres significatifs, 3.54224", mantissa)
+	}
+
+	tests := []struct {
+		v         *big.Int
+		precision int
+		want      string
+	}{
+		{f100, 3, "3.54×10²⁰"}, // Tronquée : 3.542 ne devient pas 3.55
+		{big.NewInt(199), 2, "1.9×10²"},
+		{big.NewInt(7), 6, "7"},
+		{big.NewInt(12), 6, "1.2×10¹"},
+		{f100, 1, "3×10²⁰"},
+	}
+	for _, tt := range tests {
+		if got := formatBigIntSup(tt.v, tt.precision); got != tt.want {
+			t.Errorf("formatBigIntSup(%s, %d) = %q ; attendu %q", tt.v, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestFormatInBase(t *testing.T) {
+	f10, _ := fibDoublingPair(10) // 55
+	tests := []struct {
+		binary bool
+		base   int
+		want   string
+	}{
+		{false, 0, ""},
+		{true, 0, "0b110111"},
+		{false, 16, "37"},
+		{true, 16, "37"}, // -base prime sur -binary
+		{false, 2, "110111"},
+		{false, 36, "1j"},
+	}
+	for _, tt := range tests {
+		config := DefaultConfig()
+		config.Binary, config.Base = tt.binary, tt.base
+		if got := formatInBase(f10, config); got != tt.want {
+			t.Errorf("formatInBase(55, -binary %v, -base %d) = %q ; attendu %q", tt.binary, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBase(t *testing.T) {
+	for base, valid := range map[int]bool{0: true, 1: false, 2: true, 16: true, 36: true, 37: false, -2: false} {
+		config := DefaultConfig()
+		config.Base = base
+		if err := config.Validate(); (err == nil) != valid {
+			t.Errorf("Validate(-base %d) = %v ; valide attendu : %v", base, err, valid)
+		}
+	}
+}
+
+func TestApplyMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	config, err := parseFlagSet(flag.NewFlagSet("Doubling", flag.ContinueOnError), []string{"-max-procs", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyMaxProcs(config.MaxProcs)
+	if got := runtime.GOMAXPROCS(-1); got != 2 {
+		t.Errorf("GOMAXPROCS = %d après -max-procs 2 ; attendu 2", got)
+	}
+	applyMaxProcs(0)
+	if got := runtime.GOMAXPROCS(-1); got != runtime.NumCPU() {
+		t.Errorf("GOMAXPROCS = %d sans -max-procs ; attendu NumCPU = %d", got, runtime.NumCPU())
+	}
+
+	config.MaxProcs = -1
+	if err := config.Validate(); err == nil {
+		t.Error("Validate(-max-procs -1) : erreur attendue")
+	}
+}
+
+func TestSequenceIndexOffset(t *testing.T) {
+	tests := []struct {
+		n, offset int
+		want      int64 // Valeur attendue de F à l'indice traduit
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{10, 0, 55},
+		{1, 1, 1},
+		{2, 1, 1},
+		{3, 1, 2},
+		{10, 1, 55},
+	}
+	for _, tt := range tests {
+		index, err := sequenceIndex(tt.n, tt.offset)
+		if err != nil {
+			t.Errorf("sequenceIndex(%d, -offset %d) : %v", tt.n, tt.offset, err)
+			continue
+		}
+		if got, _ := fibDoublingPair(index); got.Int64() != tt.want {
+			t.Errorf("-offset %d -n %d : %s ; attendu %d", tt.offset, tt.n, got, tt.want)
+		}
+	}
+	for _, bad := range [][2]int{{0, 1}, {-1, 1}, {5, 2}, {5, -1}} {
+		if _, err := sequenceIndex(bad[0], bad[1]); err == nil {
+			t.Errorf("sequenceIndex(%d, -offset %d) : erreur attendue", bad[0], bad[1])
+		}
+	}
+}
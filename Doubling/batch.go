@@ -0,0 +1,210 @@
+// =============================================================================
+// Calcul par lot à partir d'un fichier d'indices ou de l'entrée standard.
+//
+// Le fichier passé à -input-file contient des indices entiers séparés par des
+// espaces ou des retours à la ligne. Chaque indice est calculé par la même
+// Calculation que le mode calcul unique (-lucas, -mod, -sum, -cache-dir), et
+// chaque passe sur le fichier est bornée par -timeout ou -deadline. Avec
+// -watch, le fichier est surveillé par scrutation périodique de sa date de
+// modification et de sa taille : chaque modification (une fois stabilisée)
+// déclenche un nouveau calcul complet.
+//
+// Avec -stdin, les indices sont lus sur l'entrée standard et chaque résultat
+// est écrit dès qu'il est calculé, dans l'ordre de lecture et au format choisi
+// par -format, ce qui permet de chaîner le programme dans un pipeline.
+// =============================================================================
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"time"
+)
+
+const (
+	watchInterval = 500 * time.Millisecond // Période de scrutation du fichier surveillé
+	watchDebounce = 200 * time.Millisecond // Délai de stabilisation avant recalcul
+)
+
+// readIndices lit les indices entiers contenus dans le fichier path.
+func readIndices(path string) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var indices []int
+	scanner := bufio.NewScanner(f)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		n, err := parseIndex(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, n)
+	}
+	return indices, scanner.Err()
+}
+
+// runInputFile calcule, pour chaque indice du fichier path, la valeur décrite
+// par calc et l'écrit sur w comme le mode calcul unique, en notation
+// scientifique à precision chiffres significatifs. Une erreur sur un indice est
+// signalée sans interrompre les suivants ; l'annulation de ctx (-timeout,
+// -deadline) arrête le lot et est retournée.
+func runInputFile(ctx context.Context, w io.Writer, path string, calc *Calculation, offset, precision int) error {
+	indices, err := readIndices(path)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range indices {
+		index, err := sequenceIndex(n, offset)
+		if err != nil {
+			fmt.Fprintf(w, "  %s(%d) : erreur : %v\n", calc.Label, n, err)
+			continue
+		}
+		v, err := runUntilDone(ctx, func() (*big.Int, error) {
+			v, _, err := calc.Run(index)
+			return v, err
+		})
+		if ctx.Err() != nil {
+			return fmt.Errorf("délai d'exécution dépassé : %w", ctx.Err())
+		}
+		if err != nil {
+			fmt.Fprintf(w, "  %s(%d) : erreur : %v\n", calc.Label, n, err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\n", calc.Describe(n, v, precision))
+	}
+	return nil
+}
+
+// stdinError est l'enregistrement JSON émis pour une entrée qui n'a pas pu être calculée.
+type stdinError struct {
+	Input string `json:"input"` // Jeton lu sur l'entrée
+	Error string `json:"error"` // Description de l'erreur
+}
+
+// runStdin lit des indices sur r et écrit chaque résultat sur w au format
+// demandé. Un jeton invalide ou un calcul en échec produit un enregistrement
+// d'erreur (JSON sur w, texte sur la sortie d'erreur) sans arrêter la lecture.
+// Si pretty est vrai, les enregistrements JSON sont indentés.
+func runStdin(r io.Reader, w io.Writer, calc *Calculation, offset int, format string, pretty bool) error {
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	reportError := func(input string, err error) error {
+		if format == FormatJSON {
+			return newJSONEncoder(out, pretty).Encode(stdinError{Input: input, Error: err.Error()})
+		}
+		log.Printf("Entrée %q : %v", input, err)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		token := scanner.Text()
+		n, fib, err := evaluateToken(calc, token, offset)
+		if err != nil {
+			if werr := reportError(token, err); werr != nil {
+				return werr
+			}
+		} else if err := writeResult(out, format, resultRecord{N: n, Value: fib, Pretty: pretty}); err != nil {
+			return err // Erreur d'écriture : inutile de continuer
+		}
+		// Chaque résultat est transmis immédiatement au reste du pipeline.
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// evaluateToken interprète token comme un indice selon la convention offset et
+// retourne l'indice saisi avec la valeur calculée.
+func evaluateToken(calc *Calculation, token string, offset int) (int, *big.Int, error) {
+	n, err := parseIndex(token)
+	if err != nil {
+		return 0, nil, err
+	}
+	index, err := sequenceIndex(n, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	fib, _, err := calc.Run(index)
+	return n, fib, err
+}
+
+// fileState capture ce qui permet de détecter une modification du fichier.
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// statFile retourne l'état courant du fichier path.
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// watchInputFile exécute run une première fois puis à chaque modification du
+// fichier path, jusqu'à l'annulation du contexte. Les modifications rapprochées
+// sont regroupées : le recalcul n'a lieu qu'une fois l'état du fichier stable
+// pendant debounce.
+func watchInputFile(ctx context.Context, path string, interval, debounce time.Duration, run func()) {
+	last := statFile(path)
+	if last.exists {
+		run()
+	} else {
+		log.Printf("Fichier %s introuvable, en attente de sa création", path)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := statFile(path)
+		if current == last {
+			continue
+		}
+
+		// Attendre que le fichier cesse de changer avant de recalculer.
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(debounce):
+			}
+			settled := statFile(path)
+			if settled == current {
+				break
+			}
+			current = settled
+		}
+
+		last = current
+		if !current.exists {
+			log.Printf("Fichier %s supprimé, en attente de sa recréation", path)
+			continue
+		}
+		fmt.Printf("\n[%s] Modification détectée, recalcul :\n", time.Now().Format(time.TimeOnly))
+		run()
+	}
+}
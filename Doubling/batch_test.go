@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeIndexFile crée dans un répertoire temporaire un fichier d'indices de contenu content.
+func writeIndexFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "indices.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunInputFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Configuration
+		want   []string
+	}{
+		{
+			name:   "fibonacci",
+			config: Configuration{},
+			want:   []string{"  Fibonacci(10) : 5.5×10¹", "  Fibonacci(-1) : erreur : n doit être non négatif", "  Fibonacci(30) : 8.32040×10⁵"},
+		},
+		{
+			name:   "somme",
+			config: Configuration{Sum: true},
+			want:   []string{"  ΣFibonacci(10) : 8.8×10¹", "  ΣFibonacci(-1) : erreur : n doit être non négatif", "  ΣFibonacci(30) : 1.34626×10⁶"},
+		},
+	}
+	path := writeIndexFile(t, "10\n-1 30\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc, err := NewCalculation(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := runInputFile(context.Background(), &out, path, calc, 0, 6); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("sortie :\n%s\nattendu :\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRunInputFileUsesCache(t *testing.T) {
+	dir := t.TempDir()
+	config := Configuration{CacheDir: dir}
+	calc, err := NewCalculation(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runInputFile(context.Background(), &bytes.Buffer{}, writeIndexFile(t, "25"), calc, 0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := calc.Cache.Get(25); !ok || v.Int64() != 75025 {
+		t.Fatalf("cache après le lot : %v, %v ; attendu 75025", v, ok)
+	}
+}
+
+func TestRunInputFileStopsOnDeadline(t *testing.T) {
+	calc, err := NewCalculation(Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out bytes.Buffer
+	if err := runInputFile(ctx, &out, writeIndexFile(t, "100000000 200000000"), calc, 0, 6); err == nil {
+		t.Fatal("erreur attendue pour un contexte annulé")
+	}
+	if out.Len() != 0 {
+		t.Errorf("aucun résultat attendu, reçu %q", out.String())
+	}
+}
+
+func TestWatchInputFileRecomputesOnChange(t *testing.T) {
+	path := writeIndexFile(t, "10")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchInputFile(ctx, path, 5*time.Millisecond, 5*time.Millisecond, func() { runs.Add(1) })
+	}()
+
+	waitFor := func(n int32) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for runs.Load() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d calcul(s) après 2 s, attendu %d", runs.Load(), n)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor(1) // Calcul initial
+	if err := os.WriteFile(path, []byte("10 20 30"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(2) // Recalcul après modification
+
+	cancel()
+	<-done
+}
+
+func TestRunStdinJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"trois indices", "10 20\n30\n", []string{`{"n":10,"result":"55"}`, `{"n":20,"result":"6765"}`, `{"n":30,"result":"832040"}`}},
+		{"jeton invalide", "10 x 12", []string{`{"n":10,"result":"55"}`, `{"input":"x","error":"indice invalide \"x\" : entier attendu"}`, `{"n":12,"result":"144"}`}},
+		{"entrée vide", "", nil},
+	}
+	calc, err := NewCalculation(Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runStdin(strings.NewReader(tt.input), &out, calc, 0, FormatJSON, false); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			dec := json.NewDecoder(&out)
+			for dec.More() {
+				var record json.RawMessage
+				if err := dec.Decode(&record); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(record))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("enregistrements :\n%s\nattendu :\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
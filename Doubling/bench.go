@@ -0,0 +1,99 @@
+// =============================================================================
+// Mode banc d'essai (-repeat) : répète le calcul et résume les durées.
+//
+// Le résultat de chaque exécution est ignoré et le cache disque n'est pas
+// consulté, afin que chaque mesure corresponde à un calcul complet.
+// =============================================================================
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// DurationStats résume une série de durées.
+type DurationStats struct {
+	Runs   int           // Nombre d'exécutions mesurées
+	Min    time.Duration // Durée la plus courte
+	Median time.Duration // Durée médiane
+	Mean   time.Duration // Durée moyenne
+	Max    time.Duration // Durée la plus longue
+	StdDev time.Duration // Écart type
+}
+
+// benchmarkRuns exécute run jusqu'à k fois et retourne la durée de chaque
+// exécution. L'annulation de ctx interrompt la série : les durées déjà
+// mesurées sont retournées avec l'erreur du contexte.
+func benchmarkRuns(ctx context.Context, k int, run func() error) ([]time.Duration, error) {
+	durations := make([]time.Duration, 0, k)
+	for range k {
+		if err := ctx.Err(); err != nil {
+			return durations, err
+		}
+		start := time.Now()
+		if err := run(); err != nil {
+			return durations, err
+		}
+		durations = append(durations, time.Since(start))
+	}
+	return durations, nil
+}
+
+// summarizeDurations calcule les statistiques d'une série non vide de durées.
+func summarizeDurations(durations []time.Duration) DurationStats {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	n := len(sorted)
+	stats := DurationStats{Runs: n, Min: sorted[0], Max: sorted[n-1]}
+	if n%2 == 1 {
+		stats.Median = sorted[n/2]
+	} else {
+		stats.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	var sum float64
+	for _, d := range sorted {
+		sum += float64(d)
+	}
+	mean := sum / float64(n)
+	var variance float64
+	for _, d := range sorted {
+		variance += (float64(d) - mean) * (float64(d) - mean)
+	}
+	stats.Mean = time.Duration(mean)
+	stats.StdDev = time.Duration(math.Sqrt(variance / float64(n)))
+	return stats
+}
+
+// printDurationStats affiche les statistiques sous forme de tableau.
+func printDurationStats(w io.Writer, stats DurationStats, requested int) {
+	fmt.Fprintf(w, "\nBanc d'essai (%d/%d exécutions) :\n", stats.Runs, requested)
+	fmt.Fprintf(w, "  Minimum    : %s\n", formatDuration(stats.Min))
+	fmt.Fprintf(w, "  Médiane    : %s\n", formatDuration(stats.Median))
+	fmt.Fprintf(w, "  Moyenne    : %s\n", formatDuration(stats.Mean))
+	fmt.Fprintf(w, "  Maximum    : %s\n", formatDuration(stats.Max))
+	fmt.Fprintf(w, "  Écart type : %s\n", formatDuration(stats.StdDev))
+}
+
+// formatDuration retourne d dans l'unité adaptée à son ordre de grandeur :
+// nanosecondes entières sous la microseconde (850ns), microsecondes à deux
+// décimales sous la milliseconde (12.34µs), puis arrondi à trois ou quatre
+// chiffres significatifs (1.5ms, 2.3s, 1m2.346s).
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Microsecond:
+		return d.String()
+	case d.Round(10*time.Nanosecond) < time.Millisecond:
+		return fmt.Sprintf("%.2fµs", float64(d)/float64(time.Microsecond))
+	case d < time.Second:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
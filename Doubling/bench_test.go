@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBenchmarkRunsMeasuresEachRun(t *testing.T) {
+	const (
+		k     = 5
+		sleep = 20 * time.Millisecond
+	)
+	calls := 0
+	durations, err := benchmarkRuns(context.Background(), k, func() error {
+		calls++
+		time.Sleep(sleep)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("benchmarkRuns : %v", err)
+	}
+	if calls != k || len(durations) != k {
+		t.Fatalf("%d appels et %d durées ; attendu %d", calls, len(durations), k)
+	}
+
+	stats := summarizeDurations(durations)
+	if stats.Runs != k {
+		t.Errorf("Runs = %d ; attendu %d", stats.Runs, k)
+	}
+	// time.Sleep ne rend jamais la main avant l'échéance ; la marge haute
+	// absorbe la latence de l'ordonnanceur.
+	if stats.Mean < sleep || stats.Mean > sleep+15*time.Millisecond {
+		t.Errorf("Moyenne = %v ; attendu environ %v", stats.Mean, sleep)
+	}
+	if stats.Min > stats.Median || stats.Median > stats.Max {
+		t.Errorf("Ordre incohérent : min %v, médiane %v, max %v", stats.Min, stats.Median, stats.Max)
+	}
+}
+
+func TestBenchmarkRunsStopsOnError(t *testing.T) {
+	errRun := errors.New("échec simulé")
+	calls := 0
+	durations, err := benchmarkRuns(context.Background(), 5, func() error {
+		calls++
+		if calls == 3 {
+			return errRun
+		}
+		return nil
+	})
+	if !errors.Is(err, errRun) {
+		t.Fatalf("err = %v ; attendu %v", err, errRun)
+	}
+	if len(durations) != 2 {
+		t.Errorf("%d durées ; attendu les 2 exécutions réussies", len(durations))
+	}
+}
+
+func TestBenchmarkRunsStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	durations, err := benchmarkRuns(ctx, 5, func() error {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v ; attendu context.Canceled", err)
+	}
+	if calls != 2 || len(durations) != 2 {
+		t.Errorf("%d appels et %d durées ; attendu 2", calls, len(durations))
+	}
+}
+
+func TestSummarizeDurations(t *testing.T) {
+	stats := summarizeDurations([]time.Duration{4, 1, 3, 2})
+	want := DurationStats{Runs: 4, Min: 1, Median: 2, Mean: 2, Max: 4, StdDev: 1}
+	// Médiane (2+3)/2 et moyenne 2.5 sont tronquées à l'entier ; l'écart type
+	// exact vaut √1.25 ≈ 1.118.
+	if stats != want {
+		t.Errorf("summarizeDurations = %+v ; attendu %+v", stats, want)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{850 * time.Nanosecond, "850ns"},
+		{12340 * time.Nanosecond, "12.34µs"},
+		{12344 * time.Nanosecond, "12.34µs"},
+		{999996 * time.Nanosecond, "1ms"},
+		{1500 * time.Microsecond, "1.5ms"},
+		{1500004 * time.Nanosecond, "1.5ms"},
+		{2300 * time.Millisecond, "2.3s"},
+		{62345678 * time.Microsecond, "1m2.346s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%d) = %q ; attendu %q", int64(tt.d), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// =============================================================================
+// Cache disque des résultats entre deux exécutions.
+//
+// Chaque résultat est stocké dans son propre fichier compressé (gzip) sous le
+// répertoire passé à -cache-dir. Le nom du fichier combine un espace de noms
+// (suite calculée et module éventuel) et l'indice n. L'écriture passe par un
+// fichier temporaire renommé à la fin, de sorte que des processus concurrents
+// ne lisent jamais un fichier partiellement écrit. Un fichier illisible est
+// considéré comme corrompu : il est supprimé et traité comme une absence.
+// =============================================================================
+
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+// ResultCache est un cache disque de résultats indexés par n. Un *ResultCache
+// nil est valide et se comporte comme un cache toujours vide.
+type ResultCache struct {
+	dir       string // Répertoire des fichiers de cache
+	namespace string // Préfixe distinguant les suites et les modules
+}
+
+// NewResultCache crée (si nécessaire) le répertoire dir et retourne un cache
+// dont les entrées sont préfixées par namespace.
+func NewResultCache(dir, namespace string) (*ResultCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("création du répertoire de cache : %w", err)
+	}
+	return &ResultCache{dir: dir, namespace: namespace}, nil
+}
+
+// cacheNamespace retourne l'espace de noms correspondant à la configuration :
+// la suite calculée, suivie d'une empreinte du module s'il est défini.
+func cacheNamespace(c Configuration) string {
+	namespace := "fib"
+	switch {
+	case c.Lucas:
+		namespace = "lucas"
+	case c.Sum:
+		namespace = "sum"
+	}
+	if c.Mod != "" {
+		sum := sha256.Sum256([]byte(c.Mod))
+		namespace += "-mod-" + hex.EncodeToString(sum[:8])
+	}
+	return namespace
+}
+
+// path retourne le chemin du fichier associé à l'indice n.
+func (c *ResultCache) path(n uint64) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s-%d.gz", c.namespace, n))
+}
+
+// Get retourne la valeur associée à n si elle est présente et lisible.
+func (c *ResultCache) Get(n uint64) (*big.Int, bool) {
+	if c == nil {
+		return nil, false
+	}
+	path := c.path(n)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	v, err := readCacheEntry(f)
+	if err != nil {
+		// Entrée corrompue : on la supprime pour qu'elle soit recalculée.
+		os.Remove(path)
+		return nil, false
+	}
+	return v, true
+}
+
+// readCacheEntry décode une entrée de cache (gzip d'un big.Int encodé par GobEncode).
+func readCacheEntry(f *os.File) (*big.Int, error) {
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	v := new(big.Int)
+	if err := v.GobDecode(data); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Put enregistre v pour l'indice n. L'entrée est écrite dans un fichier
+// temporaire du même répertoire puis renommée atomiquement.
+func (c *ResultCache) Put(n uint64, v *big.Int) error {
+	if c == nil {
+		return nil
+	}
+	data, err := v.GobEncode()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, c.namespace+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Sans effet une fois le fichier renommé
+
+	zw := gzip.NewWriter(tmp)
+	if _, err := zw.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(n))
+}
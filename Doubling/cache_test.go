@@ -0,0 +1,137 @@
+package main
+
+import (
+	"compress/gzip"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResultCacheHitAndMiss(t *testing.T) {
+	cache, err := NewResultCache(filepath.Join(t.TempDir(), "cache"), "fib")
+	if err != nil {
+		t.Fatalf("NewResultCache : %v", err)
+	}
+	if _, ok := cache.Get(1000); ok {
+		t.Fatal("Get sur un cache vide : succès inattendu")
+	}
+	want, _ := fibDoublingPair(1000)
+	if err := cache.Put(1000, want); err != nil {
+		t.Fatalf("Put : %v", err)
+	}
+	got, ok := cache.Get(1000)
+	if !ok || got.Cmp(want) != 0 {
+		t.Fatalf("Get(1000) = %v, %v ; attendu F(1000)", got, ok)
+	}
+	if _, ok := cache.Get(1001); ok {
+		t.Error("Get(1001) : succès inattendu, seul F(1000) est enregistré")
+	}
+	entries, _ := os.ReadDir(cache.dir)
+	if len(entries) != 1 {
+		t.Errorf("%d fichiers dans le cache, attendu 1 (fichier temporaire résiduel ?)", len(entries))
+	}
+}
+
+func TestResultCacheRecoversFromCorruption(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(path string) error
+	}{
+		{"pas du gzip", func(path string) error { return os.WriteFile(path, []byte("corrompu"), 0o644) }},
+		{"gzip tronqué", func(path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, data[:len(data)/2], 0o644)
+		}},
+		{"contenu invalide", func(path string) error {
+			f, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			zw := gzip.NewWriter(f)
+			zw.Write([]byte{0xff, 0x00}) // Version d'encodage inconnue de GobDecode
+			zw.Close()
+			return f.Close()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := NewResultCache(t.TempDir(), "fib")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cache.Put(500, big.NewInt(1)); err != nil {
+				t.Fatal(err)
+			}
+			path := cache.path(500)
+			if err := tt.write(path); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := cache.Get(500); ok {
+				t.Fatal("entrée corrompue servie par le cache")
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("entrée corrompue non supprimée (%v)", err)
+			}
+			// L'entrée est recalculée et enregistrée de nouveau normalement.
+			want, _ := fibDoublingPair(500)
+			if err := cache.Put(500, want); err != nil {
+				t.Fatal(err)
+			}
+			if got, ok := cache.Get(500); !ok || got.Cmp(want) != 0 {
+				t.Errorf("Get après réécriture = %v, %v ; attendu F(500)", got, ok)
+			}
+		})
+	}
+}
+
+func TestNilResultCache(t *testing.T) {
+	var cache *ResultCache
+	if err := cache.Put(10, big.NewInt(55)); err != nil {
+		t.Errorf("Put sur un cache nil : %v", err)
+	}
+	if _, ok := cache.Get(10); ok {
+		t.Error("Get sur un cache nil : succès inattendu")
+	}
+}
+
+func TestCacheNamespace(t *testing.T) {
+	tests := []struct {
+		config Configuration
+		want   string
+	}{
+		{Configuration{}, "fib"},
+		{Configuration{Lucas: true}, "lucas"},
+		{Configuration{Sum: true}, "sum"},
+	}
+	for _, tt := range tests {
+		if got := cacheNamespace(tt.config); got != tt.want {
+			t.Errorf("cacheNamespace(%+v) = %q, attendu %q", tt.config, got, tt.want)
+		}
+	}
+	a, b := cacheNamespace(Configuration{Mod: "7"}), cacheNamespace(Configuration{Mod: "11"})
+	if a == b || a == "fib" {
+		t.Errorf("espaces de noms modulaires %q et %q : attendus distincts de fib et entre eux", a, b)
+	}
+}
+
+func TestCalculationRunUsesCache(t *testing.T) {
+	calc, err := NewCalculation(Configuration{CacheDir: t.TempDir(), Lucas: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, fromCache, err := calc.Run(300)
+	if err != nil || fromCache {
+		t.Fatalf("premier Run(300) : fromCache = %v, err = %v ; attendu un calcul", fromCache, err)
+	}
+	second, fromCache, err := calc.Run(300)
+	if err != nil || !fromCache {
+		t.Fatalf("second Run(300) : fromCache = %v, err = %v ; attendu le cache", fromCache, err)
+	}
+	if first.Cmp(second) != 0 {
+		t.Error("valeur du cache différente de la valeur calculée")
+	}
+}
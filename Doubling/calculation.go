@@ -0,0 +1,145 @@
+// =============================================================================
+// Description du calcul demandé par la configuration.
+//
+// Calculation regroupe la suite calculée (Fibonacci, Lucas ou somme des
+// premiers termes de Fibonacci), le module éventuel et le cache disque. Le
+// calcul unique, le mode lot (-input-file) et la lecture sur l'entrée standard
+// (-stdin) construisent tous leur Calculation par NewCalculation : -lucas, -mod,
+// -sum et -cache-dir s'y appliquent de la même façon. L'affichage et la durée
+// restent propres à chaque mode : -timeout et -deadline bornent le calcul
+// unique et chaque passe de -input-file, mais pas -stdin, qui traite un flux
+// sans fin prévue.
+// =============================================================================
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+)
+
+// Calculation applique à un indice le calcul décrit par la configuration.
+type Calculation struct {
+	Label   string       // Nom de la suite calculée ("Fibonacci", "Lucas" ou "ΣFibonacci")
+	Modulus *big.Int     // Module appliqué au résultat (nil si absent)
+	Cache   *ResultCache // Cache disque des résultats (nil si désactivé)
+	compute func(n int) (*big.Int, error)
+}
+
+// NewCalculation construit le calcul correspondant à la configuration.
+func NewCalculation(config Configuration) (*Calculation, error) {
+	fc := NewFibCalculator()
+	if config.Checkpoint != "" || config.Resume != "" {
+		cp := &checkpointer{path: config.Checkpoint, every: config.CheckpointEvery}
+		if config.Resume != "" {
+			resume, err := loadCheckpoint(config.Resume)
+			if err != nil {
+				return nil, err
+			}
+			cp.resume = resume
+			if cp.path == "" {
+				cp.path = config.Resume
+			}
+		}
+		fc.pair = cp.pair
+	}
+	calc := &Calculation{Label: "Fibonacci", compute: fc.Calculate}
+	switch {
+	case config.Lucas:
+		calc.Label, calc.compute = "Lucas", fc.CalculateLucas
+	case config.Sum:
+		calc.Label, calc.compute = "ΣFibonacci", fc.PrefixSum
+	}
+
+	modulus, err := parseModulus(config.Mod)
+	if err != nil {
+		return nil, err
+	}
+	if modulus != nil {
+		calc.Modulus = modulus
+		lucas, sum := config.Lucas, config.Sum
+		calc.compute = func(n int) (*big.Int, error) {
+			if n < 0 {
+				return nil, fmt.Errorf("n doit être non négatif")
+			}
+			fn, fn1 := fibDoublingPairMod(n, modulus)
+			switch {
+			case lucas:
+				// L(n) = 2·F(n+1) - F(n), ramené dans [0, m).
+				l := new(big.Int).Lsh(fn1, 1)
+				return l.Mod(l.Sub(l, fn), modulus), nil
+			case sum:
+				// Σ F(i) = F(n+1) - 1 (i < n), ramené dans [0, m).
+				return fn1.Mod(fn1.Sub(fn1, big.NewInt(1)), modulus), nil
+			default:
+				return fn, nil
+			}
+		}
+	}
+
+	if config.CacheDir != "" {
+		calc.Cache, err = NewResultCache(config.CacheDir, cacheNamespace(config))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return calc, nil
+}
+
+// Compute calcule la valeur associée à l'indice interne n sans consulter le cache.
+func (c *Calculation) Compute(n int) (*big.Int, error) {
+	return c.compute(n)
+}
+
+// Run retourne la valeur associée à l'indice interne n. Le cache est consulté
+// avant le calcul ; un résultat calculé y est ensuite enregistré.
+func (c *Calculation) Run(n int) (v *big.Int, fromCache bool, err error) {
+	if n >= 0 {
+		if cached, ok := c.Cache.Get(uint64(n)); ok {
+			return cached, true, nil
+		}
+	}
+	v, err = c.compute(n)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := c.Cache.Put(uint64(n), v); err != nil {
+		log.Printf("Impossible d'enregistrer le résultat dans le cache : %v", err)
+	}
+	return v, false, nil
+}
+
+// Describe rend le résultat v de l'indice saisi n comme le mode calcul unique :
+// un résidu modulaire, de la taille du module, en entier suivi de « mod m », et
+// toute autre valeur en notation scientifique à precision chiffres significatifs.
+func (c *Calculation) Describe(n int, v *big.Int, precision int) string {
+	if c.Modulus != nil {
+		return fmt.Sprintf("%s(%d) mod %s : %s", c.Label, n, c.Modulus, v)
+	}
+	return fmt.Sprintf("%s(%d) : %s", c.Label, n, formatBigIntSup(v, precision))
+}
+
+// runUntilDone exécute f dans une goroutine et retourne son résultat, ou
+// l'erreur de ctx si ctx est annulé avant la fin de f. Les opérations sur
+// big.Int n'étant pas interruptibles, f poursuit alors en arrière-plan :
+// l'appelant doit abandonner le résultat, en général en terminant le processus.
+func runUntilDone[T any](ctx context.Context, f func() (T, error)) (T, error) {
+	type outcome struct {
+		v   T
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		v, err := f()
+		done <- outcome{v, err}
+	}()
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case o := <-done:
+		return o.v, o.err
+	}
+}
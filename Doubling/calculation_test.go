@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// lucasReference retourne L(0), …, L(n) par additions successives.
+func lucasReference(n int) []*big.Int {
+	l := []*big.Int{big.NewInt(2), big.NewInt(1)}
+	for i := 2; i <= n; i++ {
+		l = append(l, new(big.Int).Add(l[i-1], l[i-2]))
+	}
+	return l[:n+1]
+}
+
+func TestCalculateLucasMatchesReference(t *testing.T) {
+	fc := NewFibCalculator()
+	for n, want := range lucasReference(100) {
+		got, err := fc.CalculateLucas(n)
+		if err != nil {
+			t.Fatalf("CalculateLucas(%d) : %v", n, err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("L(%d) = %s, attendu %s", n, got, want)
+		}
+	}
+}
+
+func TestCalculationLucas(t *testing.T) {
+	calc, err := NewCalculation(Configuration{Lucas: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calc.Label != "Lucas" {
+		t.Errorf("Label = %q, attendu Lucas", calc.Label)
+	}
+	for _, tt := range []struct {
+		n    int
+		want string
+	}{{0, "2"}, {1, "1"}, {10, "123"}, {30, "1860498"}} {
+		v, _, err := calc.Run(tt.n)
+		if err != nil || v.String() != tt.want {
+			t.Errorf("L(%d) = %v, %v ; attendu %s", tt.n, v, err, tt.want)
+		}
+	}
+}
+
+func TestRunInputFileLucas(t *testing.T) {
+	calc, err := NewCalculation(Configuration{Lucas: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runInputFile(context.Background(), &out, writeIndexFile(t, "10 30"), calc, 0, 6); err != nil {
+		t.Fatal(err)
+	}
+	want := "  Lucas(10) : 1.23×10²\n  Lucas(30) : 1.86049×10⁶\n"
+	if out.String() != want {
+		t.Errorf("sortie %q, attendu %q", out.String(), want)
+	}
+	if strings.Contains(out.String(), "Fibonacci") {
+		t.Error("-input-file -lucas ne doit pas afficher F(n)")
+	}
+}
+
+func TestCalculationModMatchesReference(t *testing.T) {
+	moduli := []string{"1", "2", "7", "10", "1000000007", "340282366920938463463374607431768211507"}
+	for _, tt := range []struct {
+		name   string
+		config Configuration
+		ref    func(n int) *big.Int
+	}{
+		{"fibonacci", Configuration{}, func(n int) *big.Int { f, _ := fibDoublingPair(n); return f }},
+		{"lucas", Configuration{Lucas: true}, func(n int) *big.Int { return lucasReference(n)[n] }},
+		{"somme", Configuration{Sum: true}, func(n int) *big.Int {
+			f, _ := fibDoublingPair(n + 1)
+			return f.Sub(f, big.NewInt(1))
+		}},
+	} {
+		for _, mod := range moduli {
+			config := tt.config
+			config.Mod = mod
+			calc, err := NewCalculation(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, _ := new(big.Int).SetString(mod, 10)
+			for n := 0; n <= 300; n += 7 {
+				got, err := calc.Compute(n)
+				if err != nil {
+					t.Fatalf("%s(%d) mod %s : %v", tt.name, n, mod, err)
+				}
+				if want := new(big.Int).Mod(tt.ref(n), m); got.Cmp(want) != 0 {
+					t.Errorf("%s(%d) mod %s = %s, attendu %s", tt.name, n, mod, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCalculationModHugeIndex(t *testing.T) {
+	calc, err := NewCalculation(Configuration{Mod: "1000000007"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// F(10^18) mod 10^9+7, calculé indépendamment : F(10^18) compte environ 2·10^17 chiffres.
+	got, err := calc.Compute(1_000_000_000_000_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "209783453" {
+		t.Errorf("F(10^18) mod 10^9+7 = %s, attendu 209783453", got)
+	}
+}
+
+func TestRunInputFileMod(t *testing.T) {
+	calc, err := NewCalculation(Configuration{Mod: "7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runInputFile(context.Background(), &out, writeIndexFile(t, "10 20"), calc, 0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if want := "  Fibonacci(10) mod 7 : 6\n  Fibonacci(20) mod 7 : 3\n"; out.String() != want {
+		t.Errorf("sortie %q, attendu %q", out.String(), want)
+	}
+}
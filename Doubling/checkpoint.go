@@ -0,0 +1,120 @@
+// =============================================================================
+// Points de reprise du calcul par doublement.
+//
+// Pour de très grands n, le calcul peut durer assez longtemps pour qu'une
+// interruption soit coûteuse. Avec -checkpoint, l'état de la boucle du
+// doublement (indice visé, position du prochain bit, couple (F(k), F(k+1))) est
+// enregistré toutes les -checkpoint-every itérations ; -resume repart de cet
+// état au lieu de recommencer. Le fichier est encodé avec encoding/gob, écrit
+// dans un fichier temporaire puis renommé, de sorte qu'une interruption pendant
+// l'écriture laisse intact le point de reprise précédent. Il est supprimé une
+// fois le calcul terminé.
+// =============================================================================
+
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"log"
+	"math/big"
+	"math/rand/v2"
+	"os"
+)
+
+// doublingCheckpoint est l'état minimal permettant de reprendre la boucle du doublement.
+type doublingCheckpoint struct {
+	N    int      // Indice visé
+	Next int      // Position du prochain bit de N à traiter
+	A, B *big.Int // (F(k), F(k+1)) pour k = N >> (Next+1)
+}
+
+// loadCheckpoint lit le point de reprise enregistré dans path.
+func loadCheckpoint(path string) (*doublingCheckpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cp doublingCheckpoint
+	if err := gob.NewDecoder(f).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("point de reprise %s illisible : %w", path, err)
+	}
+	if cp.A == nil {
+		cp.A = new(big.Int) // gob n'émet pas les valeurs nulles
+	}
+	if cp.B == nil {
+		cp.B = new(big.Int)
+	}
+	if cp.N < 0 || cp.Next < 0 || cp.Next >= highestBit(cp.N) {
+		return nil, fmt.Errorf("point de reprise %s incohérent (n = %d, bit %d)", path, cp.N, cp.Next)
+	}
+	return &cp, nil
+}
+
+// saveCheckpoint enregistre cp dans path par écriture d'un fichier temporaire
+// du même répertoire renommé atomiquement.
+func saveCheckpoint(path string, cp doublingCheckpoint) error {
+	tmp, err := createSibling(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Sans effet une fois le fichier renommé
+
+	if err := gob.NewEncoder(tmp).Encode(cp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// createSibling crée un fichier temporaire inédit dans le répertoire de path.
+// Contrairement à os.CreateTemp (mode 0600), ses droits sont ceux d'os.Create
+// (0666 filtré par l'umask), comme pour les autres fichiers produits.
+func createSibling(path string) (*os.File, error) {
+	for {
+		name := fmt.Sprintf("%s-%d.tmp", path, rand.Uint64())
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+}
+
+// checkpointer calcule le couple (F(n), F(n+1)) par doublement en enregistrant
+// un point de reprise toutes les every itérations.
+type checkpointer struct {
+	path   string              // Fichier du point de reprise
+	every  int                 // Nombre d'itérations entre deux enregistrements
+	resume *doublingCheckpoint // État de départ (nil pour partir de zéro)
+}
+
+// pair a la même sémantique que fibDoublingPair. Le point de reprise éventuel
+// n'est utilisé qu'une fois et doit porter sur le même indice n.
+func (c *checkpointer) pair(n int) (*big.Int, *big.Int, error) {
+	a, b, next := big.NewInt(0), big.NewInt(1), highestBit(n)
+	if cp := c.resume; cp != nil {
+		if cp.N != n {
+			return nil, nil, fmt.Errorf("le point de reprise porte sur n = %d, pas sur n = %d", cp.N, n)
+		}
+		a, b, next = cp.A, cp.B, cp.Next
+		c.resume = nil
+	}
+
+	for i, steps := next, 1; i >= 0; i, steps = i-1, steps+1 {
+		doublingStep(a, b, n&(1<<uint(i)) != 0)
+		if steps%c.every == 0 && i > 0 {
+			if err := saveCheckpoint(c.path, doublingCheckpoint{N: n, Next: i - 1, A: a, B: b}); err != nil {
+				log.Printf("Impossible d'enregistrer le point de reprise : %v", err)
+			}
+		}
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Impossible de supprimer le point de reprise : %v", err)
+	}
+	return a, b, nil
+}
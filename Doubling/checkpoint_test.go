@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// partialCheckpoint exécute la boucle du doublement de n jusqu'au bit stop
+// (exclu) et retourne l'état qu'un point de reprise enregistrerait alors.
+func partialCheckpoint(n, stop int) doublingCheckpoint {
+	a, b := big.NewInt(0), big.NewInt(1)
+	for i := highestBit(n); i > stop; i-- {
+		doublingStep(a, b, n&(1<<uint(i)) != 0)
+	}
+	return doublingCheckpoint{N: n, Next: stop, A: a, B: b}
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fib.ckpt")
+	want := partialCheckpoint(1_000_003, 7)
+	if err := saveCheckpoint(path, want); err != nil {
+		t.Fatalf("saveCheckpoint : %v", err)
+	}
+	got, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint : %v", err)
+	}
+	if got.N != want.N || got.Next != want.Next || got.A.Cmp(want.A) != 0 || got.B.Cmp(want.B) != 0 {
+		t.Errorf("point de reprise relu = {%d %d}, attendu {%d %d}", got.N, got.Next, want.N, want.Next)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("%d fichiers dans le répertoire, attendu le seul point de reprise", len(entries))
+	}
+}
+
+func TestCheckpointFileModeMatchesOutputFiles(t *testing.T) {
+	dir := t.TempDir()
+	ref, err := os.Create(filepath.Join(dir, "ref"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref.Close()
+	path := filepath.Join(dir, "fib.ckpt")
+	if err := saveCheckpoint(path, partialCheckpoint(100, 2)); err != nil {
+		t.Fatalf("saveCheckpoint : %v", err)
+	}
+	refInfo, _ := os.Stat(ref.Name())
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != refInfo.Mode().Perm() {
+		t.Errorf("droits du point de reprise = %v, attendu %v (comme os.Create)", info.Mode().Perm(), refInfo.Mode().Perm())
+	}
+}
+
+func TestResumeMatchesUninterruptedCalculation(t *testing.T) {
+	for _, n := range []int{2, 3, 100, 4097, 1_000_003} {
+		path := filepath.Join(t.TempDir(), "fib.ckpt")
+		for stop := highestBit(n) - 1; stop >= 0; stop -= max(highestBit(n)/4, 1) {
+			if err := saveCheckpoint(path, partialCheckpoint(n, stop)); err != nil {
+				t.Fatalf("saveCheckpoint : %v", err)
+			}
+			calc, err := NewCalculation(Configuration{Resume: path, CheckpointEvery: 1})
+			if err != nil {
+				t.Fatalf("NewCalculation : %v", err)
+			}
+			got, err := calc.Compute(n)
+			if err != nil {
+				t.Fatalf("n=%d, bit %d : %v", n, stop, err)
+			}
+			if want, _ := fibDoublingPair(n); got.Cmp(want) != 0 {
+				t.Errorf("n=%d repris au bit %d : résultat différent du calcul sans reprise", n, stop)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("n=%d : point de reprise conservé après la fin du calcul (%v)", n, err)
+			}
+		}
+	}
+}
+
+func TestResumeRejectsOtherIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fib.ckpt")
+	if err := saveCheckpoint(path, partialCheckpoint(100, 2)); err != nil {
+		t.Fatalf("saveCheckpoint : %v", err)
+	}
+	calc, err := NewCalculation(Configuration{Resume: path, CheckpointEvery: 1})
+	if err != nil {
+		t.Fatalf("NewCalculation : %v", err)
+	}
+	if _, err := calc.Compute(101); err == nil {
+		t.Error("reprise d'un point enregistré pour n = 100 avec n = 101 : erreur attendue")
+	}
+}
+
+func TestLoadCheckpointRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fib.ckpt")
+	if err := os.WriteFile(path, []byte("pas un gob"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCheckpoint(path); err == nil {
+		t.Error("loadCheckpoint d'un fichier corrompu : erreur attendue")
+	}
+}
@@ -0,0 +1,82 @@
+// =============================================================================
+// Copie du résultat dans le presse-papiers du système.
+//
+// Aucune bibliothèque n'est nécessaire : la copie passe par l'utilitaire
+// standard de chaque système (pbcopy sous macOS, clip sous Windows, wl-copy,
+// xclip ou xsel ailleurs), qui lit le texte sur son entrée standard. Un
+// résultat trop volumineux n'est pas copié, pour ne pas saturer le
+// presse-papiers ; un avertissement est alors affiché.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// maxClipboardBytes est la taille au-delà de laquelle le résultat n'est pas copié.
+const maxClipboardBytes = 1 << 20
+
+// Clipboard copie un texte dans un presse-papiers.
+type Clipboard interface {
+	Copy(text string) error
+}
+
+// systemClipboard utilise l'utilitaire de copie du système.
+type systemClipboard struct{}
+
+// clipboard est le presse-papiers utilisé par -clipboard.
+var clipboard Clipboard = systemClipboard{}
+
+// clipboardCommand retourne la commande de copie disponible sur ce système.
+func clipboardCommand() ([]string, error) {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("aucun utilitaire de presse-papiers trouvé (%s)", runtime.GOOS)
+}
+
+// Copy transmet text à l'utilitaire de copie du système.
+func (systemClipboard) Copy(text string) error {
+	args, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s : %w %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// copyToClipboard copie dans cb la représentation décimale de v, limitée à ses
+// k premiers et k derniers chiffres si k > 0 (voir truncateDigits). Un résultat
+// plus long que maxClipboardBytes n'est pas copié ; sa taille est estimée
+// d'après le nombre de bits, sans construire la chaîne décimale.
+func copyToClipboard(cb Clipboard, v *big.Int, k int) error {
+	if size := int(float64(v.BitLen())*math.Log10(2)) + 1; k <= 0 && size > maxClipboardBytes {
+		return fmt.Errorf("résultat d'environ %d chiffres non copié (limite : %d octets, voir -output-digits)", size, maxClipboardBytes)
+	}
+	return cb.Copy(truncateDigits(v.String(), k))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// fakeClipboard mémorise les textes copiés au lieu d'appeler le système.
+type fakeClipboard struct{ copies []string }
+
+func (c *fakeClipboard) Copy(text string) error {
+	c.copies = append(c.copies, text)
+	return nil
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	f100, _ := fibDoublingPair(100)
+	var cb fakeClipboard
+	if err := copyToClipboard(&cb, f100, 0); err != nil {
+		t.Fatalf("copie complète : %v", err)
+	}
+	if err := copyToClipboard(&cb, f100, 5); err != nil {
+		t.Fatalf("copie tronquée : %v", err)
+	}
+	want := []string{
+		"354224848179261915075",
+		"35422…(11 chiffres omis)…15075",
+	}
+	if len(cb.copies) != len(want) {
+		t.Fatalf("%d copies, attendu %d : %q", len(cb.copies), len(want), cb.copies)
+	}
+	for i := range want {
+		if cb.copies[i] != want[i] {
+			t.Errorf("copie %d = %q ; attendu %q", i, cb.copies[i], want[i])
+		}
+	}
+}
+
+func TestCopyToClipboardRefusesLargeResult(t *testing.T) {
+	// 10^(maxClipboardBytes+1) compte maxClipboardBytes+2 chiffres.
+	huge := new(big.Int).Exp(big.NewInt(10), big.NewInt(maxClipboardBytes+1), nil)
+	var cb fakeClipboard
+	err := copyToClipboard(&cb, huge, 0)
+	if err == nil || !strings.Contains(err.Error(), "non copié") {
+		t.Fatalf("err = %v ; attendu un refus de copie", err)
+	}
+	if len(cb.copies) != 0 {
+		t.Errorf("%d copie(s) malgré le refus", len(cb.copies))
+	}
+	// Tronqué par -output-digits, le même résultat reste copiable.
+	if err := copyToClipboard(&cb, huge, 3); err != nil {
+		t.Fatalf("copie tronquée : %v", err)
+	}
+	if len(cb.copies) != 1 || !strings.HasPrefix(cb.copies[0], "100…") || !strings.HasSuffix(cb.copies[0], "…000") {
+		t.Errorf("copie tronquée = %.40q", cb.copies)
+	}
+}
+
+func TestValidateRejectsQuietClipboard(t *testing.T) {
+	config := DefaultConfig()
+	config.Quiet, config.Clipboard = true, true
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "-clipboard") {
+		t.Errorf("Validate(-quiet -clipboard) = %v ; attendu un rejet", err)
+	}
+}
@@ -0,0 +1,82 @@
+// =============================================================================
+// Fichier de configuration (-config).
+//
+// Le fichier est un objet JSON dont les clés sont les noms des options de la
+// ligne de commande, par exemple :
+//
+//	{"n": 1000000, "timeout": "1m", "sci-precision": 10, "cache-dir": "/tmp/fib"}
+//
+// Ordre de priorité : valeurs par défaut < fichier < options de la ligne de
+// commande. Une option passée explicitement n'est donc jamais écrasée par le
+// fichier.
+// =============================================================================
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// applyConfigFile lit le fichier path et affecte chacune de ses valeurs à
+// l'option du même nom dans fs, sauf si cette option a été passée sur la ligne
+// de commande. fs doit déjà avoir été analysé.
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("lecture du fichier de configuration : %w", err)
+	}
+	var values map[string]json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return fmt.Errorf("fichier de configuration %s : %w", path, err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for name, raw := range values {
+		if name == "config" {
+			return fmt.Errorf("fichier de configuration %s : la clé %q n'est pas autorisée", path, name)
+		}
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("fichier de configuration %s : option inconnue %q", path, name)
+		}
+		if explicit[name] {
+			continue
+		}
+		value, err := configValue(raw)
+		if err != nil {
+			return fmt.Errorf("fichier de configuration %s : clé %q : %w", path, name, err)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("fichier de configuration %s : clé %q : %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// configValue convertit une valeur JSON (chaîne, nombre ou booléen) en texte
+// interprétable par flag.Value.Set.
+func configValue(raw json.RawMessage) (string, error) {
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("valeur non prise en charge %s (chaîne, nombre ou booléen attendu)", raw)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseWithConfig écrit content dans un fichier -config (s'il n'est pas vide)
+// et analyse args suivis de ce fichier.
+func parseWithConfig(t *testing.T, content string, args ...string) (Configuration, error) {
+	t.Helper()
+	if content != "" {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, "-config", path)
+	}
+	fs := flag.NewFlagSet("fib", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return parseFlagSet(fs, args)
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	def := DefaultConfig()
+	tests := []struct {
+		name     string
+		content  string
+		args     []string
+		m        int
+		timeout  time.Duration
+		lucas    bool
+		indexSet bool
+	}{
+		{"défauts", "", nil, def.M, def.Timeout, false, false},
+		{"fichier seul", `{"n": 1000, "timeout": "1m", "lucas": true}`, nil, 1000, time.Minute, true, true},
+		{"option prioritaire sur le fichier", `{"n": 1000, "timeout": "1m"}`, []string{"-n", "5", "-timeout", "2s"}, 5, 2 * time.Second, false, true},
+		{"fichier complété par la ligne de commande", `{"timeout": "1m"}`, []string{"-lucas"}, def.M, time.Minute, true, false},
+		{"booléen explicite prioritaire", `{"lucas": true}`, []string{"-lucas=false"}, def.M, def.Timeout, false, false},
+		{"-n sur la ligne de commande seule", "", []string{"-n", "1e3"}, 1000, def.Timeout, false, true},
+		{"nombre JSON et notation scientifique", `{"n": "2.5e3"}`, nil, 2500, def.Timeout, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseWithConfig(t, tt.content, tt.args...)
+			if err != nil {
+				t.Fatalf("parseFlagSet : %v", err)
+			}
+			if c.M != tt.m || c.Timeout != tt.timeout || c.Lucas != tt.lucas || c.IndexSet != tt.indexSet {
+				t.Errorf("M=%d Timeout=%v Lucas=%v IndexSet=%v ; attendu M=%d Timeout=%v Lucas=%v IndexSet=%v",
+					c.M, c.Timeout, c.Lucas, c.IndexSet, tt.m, tt.timeout, tt.lucas, tt.indexSet)
+			}
+		})
+	}
+}
+
+func TestConfigFileRejectsInvalidContent(t *testing.T) {
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{`{"inconnue": 1}`, "option inconnue"},
+		{`{"config": "autre.json"}`, "n'est pas autorisée"},
+		{`{"n": "abc"}`, `clé "n"`},
+		{`{"timeout": [1]}`, `clé "timeout"`},
+		{`pas du JSON`, "fichier de configuration"},
+	}
+	for _, tt := range tests {
+		if _, err := parseWithConfig(t, tt.content); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("fichier %s : erreur %v, attendu une erreur contenant %q", tt.content, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// =============================================================================
+// Conversion décimale par blocs d'un grand entier.
+//
+// big.Int.Text(10) matérialise d'un coup la chaîne décimale complète. Pour les
+// très grands résultats, writeDecimal découpe récursivement le nombre par des
+// puissances de 10 (diviser pour régner) et n'écrit que des blocs de taille
+// bornée, ce qui permet de traiter les chiffres au fil de l'eau : c'est ainsi
+// que le format texte (-out, -quiet, -stdin) écrit le résultat.
+// =============================================================================
+
+package main
+
+import (
+	"io"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// decimalLeafBits est la taille (en bits) en dessous de laquelle un bloc est
+// converti directement avec big.Int.Text(10) (environ 10 000 chiffres).
+const decimalLeafBits = 32768
+
+// writeDecimal écrit la représentation décimale de v sur w, bloc par bloc.
+func writeDecimal(w io.Writer, v *big.Int) error {
+	if v.Sign() < 0 {
+		if _, err := io.WriteString(w, "-"); err != nil {
+			return err
+		}
+		v = new(big.Int).Abs(v)
+	}
+	if v.BitLen() <= decimalLeafBits {
+		_, err := io.WriteString(w, v.Text(10))
+		return err
+	}
+
+	// Découpage en deux moitiés d'environ le même nombre de chiffres.
+	digits := int(float64(v.BitLen())*math.Log10(2)) + 1
+	low := digits / 2
+	high, rest := new(big.Int).QuoRem(v, pow10(low), new(big.Int))
+	if err := writeDecimal(w, high); err != nil {
+		return err
+	}
+	return writeDecimalPadded(w, rest, low)
+}
+
+// writeDecimalPadded écrit v sur exactement width chiffres, complété à gauche
+// par des zéros. v doit être strictement inférieur à 10^width.
+func writeDecimalPadded(w io.Writer, v *big.Int, width int) error {
+	if v.BitLen() <= decimalLeafBits {
+		s := v.Text(10)
+		if _, err := io.WriteString(w, strings.Repeat("0", width-len(s))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, s)
+		return err
+	}
+
+	low := width / 2
+	high, rest := new(big.Int).QuoRem(v, pow10(low), new(big.Int))
+	if err := writeDecimalPadded(w, high, width-low); err != nil {
+		return err
+	}
+	return writeDecimalPadded(w, rest, low)
+}
+
+// pow10 retourne 10^k.
+func pow10(k int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(k)), nil)
+}
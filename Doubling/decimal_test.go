@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestWriteDecimalMatchesText(t *testing.T) {
+	var values []*big.Int
+	for _, n := range []int{0, 1, 93, 1000, 50_000, 300_000} {
+		v, _ := fibDoublingPair(n)
+		values = append(values, v)
+	}
+	// Des blocs de poids faible nuls ou très courts exercent le remplissage
+	// par des zéros de writeDecimalPadded.
+	p := pow10(40_000)
+	values = append(values,
+		p,
+		new(big.Int).Add(p, big.NewInt(1)),
+		new(big.Int).Sub(p, big.NewInt(1)),
+		new(big.Int).Neg(values[len(values)-1]),
+	)
+	for _, v := range values {
+		var buf strings.Builder
+		if err := writeDecimal(&buf, v); err != nil {
+			t.Fatalf("writeDecimal : %v", err)
+		}
+		if want := v.Text(10); buf.String() != want {
+			t.Errorf("writeDecimal(%d bits) diffère de Text(10) (%d caractères, attendu %d)", v.BitLen(), buf.Len(), len(want))
+		}
+	}
+}
+
+func TestWriteDecimalPadded(t *testing.T) {
+	var buf strings.Builder
+	if err := writeDecimalPadded(&buf, big.NewInt(42), 6); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "000042" {
+		t.Errorf("writeDecimalPadded(42, 6) = %q ; attendu \"000042\"", buf.String())
+	}
+}
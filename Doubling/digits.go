@@ -0,0 +1,103 @@
+// =============================================================================
+// Nombre de chiffres décimaux de F(n) sans calculer F(n).
+//
+// Pour n ≥ 2, F(n) est l'entier le plus proche de φ^n/√5 et n'est jamais une
+// puissance de 10 (à part F(1) = F(2) = 1). Son nombre de chiffres vaut donc
+//
+//	⌊n·log10(φ) − log10(√5)⌋ + 1
+//
+// Le logarithme est évalué en big.Float. Si la partie fractionnaire est trop
+// proche d'un entier pour que l'arrondi soit sûr, la précision est doublée.
+// =============================================================================
+
+package main
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// digitCountPrec est la précision binaire initiale du calcul de DigitCount.
+const digitCountPrec = 128
+
+// DigitCount retourne le nombre de chiffres décimaux de F(n).
+func DigitCount(n uint64) uint64 {
+	if n <= 2 {
+		return 1 // F(0) = 0, F(1) = F(2) = 1
+	}
+	prec := uint(digitCountPrec + bits.Len64(n))
+	for {
+		x := fibLog10(n, prec)
+		floor, _ := x.Int(nil)
+		frac := new(big.Float).SetPrec(prec).Sub(x, new(big.Float).SetInt(floor))
+
+		// Distance de x à l'entier le plus proche, comparée à la marge d'erreur
+		// tolérée à cette précision. Au-delà de n bits, l'écart entre φ^n/√5 et
+		// une puissance de 10 est toujours résolu.
+		dist := frac
+		if frac.Cmp(big.NewFloat(0.5)) > 0 {
+			dist = new(big.Float).SetPrec(prec).Sub(big.NewFloat(1), frac)
+		}
+		margin := new(big.Float).SetMantExp(big.NewFloat(1), -int(prec/2))
+		if dist.Cmp(margin) > 0 || uint64(prec) > n+digitCountPrec {
+			return floor.Uint64() + 1
+		}
+		prec *= 2
+	}
+}
+
+// fibLog10 retourne n·log10(φ) − log10(√5) = (n·ln φ − ½·ln 5) / ln 10, calculé
+// avec prec bits de précision.
+func fibLog10(n uint64, prec uint) *big.Float {
+	work := prec + 32 // Bits de garde pour les opérations intermédiaires
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(work) }
+
+	// φ = (1 + √5) / 2
+	sqrt5 := newFloat().Sqrt(newFloat().SetInt64(5))
+	phi := newFloat().Add(sqrt5, newFloat().SetInt64(1))
+	phi.Quo(phi, newFloat().SetInt64(2))
+
+	// ln 2 = 2·atanh(1/3), ln 5 = 2·ln 2 + ln(5/4) = 2·ln 2 + 2·atanh(1/9)
+	ln2 := atanhInv(3, work)
+	ln2.Add(ln2, ln2)
+	ln5 := atanhInv(9, work)
+	ln5.Add(ln5, ln5)
+	ln5.Add(ln5, ln2)
+	ln5.Add(ln5, ln2)
+	ln10 := newFloat().Add(ln2, ln5)
+
+	// ln φ = 2·atanh((φ − 1) / (φ + 1))
+	y := newFloat().Quo(
+		newFloat().Sub(phi, newFloat().SetInt64(1)),
+		newFloat().Add(phi, newFloat().SetInt64(1)),
+	)
+	lnPhi := atanh(y, work)
+	lnPhi.Add(lnPhi, lnPhi)
+
+	x := newFloat().Mul(lnPhi, newFloat().SetUint64(n))
+	x.Sub(x, newFloat().Quo(ln5, newFloat().SetInt64(2)))
+	return x.Quo(x, ln10).SetPrec(prec)
+}
+
+// atanhInv retourne atanh(1/k) avec prec bits de précision.
+func atanhInv(k int64, prec uint) *big.Float {
+	y := new(big.Float).SetPrec(prec).SetInt64(1)
+	return atanh(y.Quo(y, new(big.Float).SetPrec(prec).SetInt64(k)), prec)
+}
+
+// atanh retourne atanh(y) = y + y³/3 + y⁵/5 + … pour |y| < 1, avec prec bits
+// de précision. La série converge d'autant plus vite que |y| est petit.
+func atanh(y *big.Float, prec uint) *big.Float {
+	sum := new(big.Float).SetPrec(prec).Set(y)
+	y2 := new(big.Float).SetPrec(prec).Mul(y, y)
+	power := new(big.Float).SetPrec(prec).Set(y)
+	term := new(big.Float).SetPrec(prec)
+	for k := int64(3); ; k += 2 {
+		power.Mul(power, y2)
+		term.Quo(power, new(big.Float).SetInt64(k))
+		if term.Sign() == 0 || term.MantExp(nil)-sum.MantExp(nil) < -int(prec) {
+			return sum
+		}
+		sum.Add(sum, term)
+	}
+}
@@ -0,0 +1,59 @@
+// =============================================================================
+// Codage de Fibonacci des entiers strictement positifs.
+//
+// Le code d'un entier x est sa représentation de Zeckendorf écrite des petits
+// indices vers les grands : le bit de position i vaut 1 si F(i+2) y figure.
+// Deux 1 consécutifs n'apparaissant jamais dans cette représentation, un 1
+// supplémentaire termine le mot, qui finit donc toujours par « 11 » ; ce code
+// préfixe permet de concaténer des entiers sans séparateur.
+// Exemples : 1 → 11, 4 = F(4) + F(2) → 1011, 11 = F(6) + F(4) → 001011.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// FibonacciEncode retourne le code de Fibonacci de x, strictement positif.
+func FibonacciEncode(x *big.Int) (string, error) {
+	indices, err := Zeckendorf(x)
+	if err != nil {
+		return "", err
+	}
+	// indices est décroissant : F(k) en tête occupe la position k-2, suivie du 1 final.
+	code := make([]byte, indices[0])
+	for i := range code {
+		code[i] = '0'
+	}
+	for _, k := range indices {
+		code[k-2] = '1'
+	}
+	code[len(code)-1] = '1'
+	return string(code), nil
+}
+
+// FibonacciDecode retourne l'entier dont code est le code de Fibonacci. Le mot
+// doit se terminer par « 11 » et ne contenir aucun autre couple de 1 consécutifs.
+func FibonacciDecode(code string) (*big.Int, error) {
+	code = strings.TrimSpace(code)
+	if strings.Trim(code, "01") != "" {
+		return nil, fmt.Errorf("code de Fibonacci invalide %q : seuls 0 et 1 sont admis", code)
+	}
+	if !strings.HasSuffix(code, "11") || strings.Contains(code[:len(code)-1], "11") {
+		return nil, fmt.Errorf("code de Fibonacci invalide %q : doit se terminer par le seul « 11 » du mot", code)
+	}
+
+	x := new(big.Int)
+	a, b := big.NewInt(1), big.NewInt(2) // F(i+2), F(i+3)
+	for i := range len(code) - 1 {
+		if code[i] == '1' {
+			x.Add(x, a)
+		}
+		a.Add(a, b)
+		a, b = b, a
+	}
+	return x, nil
+}
@@ -0,0 +1,71 @@
+// =============================================================================
+// Histogramme des chiffres décimaux d'un résultat.
+//
+// On conjecture que les chiffres de F(n) sont équidistribués lorsque n grandit.
+// DigitHistogram compte la fréquence de chaque chiffre 0–9 ; il implémente
+// io.Writer afin de recevoir les chiffres produits par writeDecimal sans que
+// la chaîne complète ne soit jamais construite.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// histogramBarWidth est la largeur maximale (en caractères) d'une barre affichée.
+const histogramBarWidth = 40
+
+// DigitHistogram compte les occurrences de chaque chiffre décimal.
+type DigitHistogram [10]int64
+
+// Write comptabilise les chiffres de p ; les autres caractères sont ignorés.
+func (h *DigitHistogram) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if c >= '0' && c <= '9' {
+			h[c-'0']++
+		}
+	}
+	return len(p), nil
+}
+
+// Total retourne le nombre total de chiffres comptabilisés.
+func (h *DigitHistogram) Total() int64 {
+	var total int64
+	for _, count := range h {
+		total += count
+	}
+	return total
+}
+
+// computeDigitHistogram retourne l'histogramme des chiffres décimaux de v.
+func computeDigitHistogram(v *big.Int) (*DigitHistogram, error) {
+	h := new(DigitHistogram)
+	if err := writeDecimal(h, new(big.Int).Abs(v)); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// printDigitHistogram affiche l'histogramme sous forme de barres horizontales.
+func printDigitHistogram(w io.Writer, h *DigitHistogram) {
+	total := h.Total()
+	var maxCount int64
+	for _, count := range h {
+		maxCount = max(maxCount, count)
+	}
+
+	fmt.Fprintf(w, "\nHistogramme des chiffres (%d chiffres) :\n", total)
+	for digit, count := range h {
+		var pct float64
+		var bar int
+		if total > 0 {
+			pct = 100 * float64(count) / float64(total)
+			bar = int(count * histogramBarWidth / maxCount)
+		}
+		fmt.Fprintf(w, "  %d : %10d (%6.2f %%) %s\n", digit, count, pct, strings.Repeat("█", bar))
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigitHistogramCountsEveryDigit(t *testing.T) {
+	// F(300 000) dépasse decimalLeafBits : le découpage par blocs, y compris les
+	// zéros de remplissage, est compté.
+	for _, n := range []int{0, 10, 100, 1000, 300_000} {
+		v, _ := fibDoublingPair(n)
+		h, err := computeDigitHistogram(v)
+		if err != nil {
+			t.Fatalf("computeDigitHistogram(F(%d)) : %v", n, err)
+		}
+		s := v.String()
+		if h.Total() != int64(len(s)) {
+			t.Errorf("F(%d) : %d chiffres comptés ; attendu %d", n, h.Total(), len(s))
+		}
+		for digit, count := range h {
+			if want := int64(strings.Count(s, string(rune('0'+digit)))); count != want {
+				t.Errorf("F(%d) : chiffre %d compté %d fois ; attendu %d", n, digit, count, want)
+			}
+		}
+	}
+}
+
+func TestPrintDigitHistogram(t *testing.T) {
+	h := DigitHistogram{5: 2} // F(10) = 55
+	var buf strings.Builder
+	printDigitHistogram(&buf, &h)
+	out := buf.String()
+	if !strings.Contains(out, "(2 chiffres)") {
+		t.Errorf("total absent :\n%s", out)
+	}
+	if !strings.Contains(out, "  5 :          2 (100.00 %) "+strings.Repeat("█", histogramBarWidth)) {
+		t.Errorf("barre du chiffre 5 incorrecte :\n%s", out)
+	}
+	if !strings.Contains(out, "  0 :          0 (  0.00 %) \n") {
+		t.Errorf("ligne du chiffre 0 incorrecte :\n%s", out)
+	}
+}
@@ -0,0 +1,118 @@
+// =============================================================================
+// Lecture des indices n saisis par l'utilisateur.
+//
+// En plus des entiers décimaux ordinaires, parseIndex accepte :
+//   - les séparateurs « _ » entre chiffres, comme en Go : 1_000_000 ;
+//   - la notation scientifique décimale lorsqu'elle désigne un entier exact :
+//     1e6, 2.5e5, 1.5E6. Une valeur non entière (1.5e0, 1.23) est refusée.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxIndexExponent borne l'exposant de la notation scientifique, afin de ne
+// pas construire d'entier démesuré avant de constater le dépassement.
+const maxIndexExponent = 40
+
+// parseIndex interprète s comme un indice entier (voir l'en-tête du fichier).
+func parseIndex(s string) (int, error) {
+	digits, err := stripDigitSeparators(s)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.ContainsAny(digits, ".eE") {
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, fmt.Errorf("indice invalide %q : entier attendu", s)
+		}
+		return n, nil
+	}
+
+	// Notation scientifique : mantisse décimale, exposant entier borné.
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(digits), "e")
+	if hasExponent {
+		exp, err := strconv.Atoi(exponent)
+		if err != nil {
+			return 0, fmt.Errorf("indice invalide %q : exposant incorrect", s)
+		}
+		if exp > maxIndexExponent || exp < -maxIndexExponent {
+			return 0, fmt.Errorf("indice hors limites %q", s)
+		}
+	}
+	if !isDecimalMantissa(mantissa) {
+		return 0, fmt.Errorf("indice invalide %q", s)
+	}
+	r, ok := new(big.Rat).SetString(digits)
+	if !ok {
+		return 0, fmt.Errorf("indice invalide %q", s)
+	}
+	if !r.IsInt() {
+		return 0, fmt.Errorf("indice invalide %q : la valeur n'est pas entière", s)
+	}
+	if !r.Num().IsInt64() || r.Num().Int64() > math.MaxInt || r.Num().Int64() < math.MinInt {
+		return 0, fmt.Errorf("indice hors limites %q", s)
+	}
+	return int(r.Num().Int64()), nil
+}
+
+// isDecimalMantissa indique si s est une mantisse décimale : un signe moins
+// facultatif, des chiffres et au plus un point, avec au moins un chiffre. Les
+// autres syntaxes de big.Rat (fractions, hexadécimal, exposant p) sont exclues.
+func isDecimalMantissa(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	digits, points := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			digits++
+		case s[i] == '.':
+			points++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && points <= 1
+}
+
+// stripDigitSeparators retire les « _ » de s après avoir vérifié que chacun
+// sépare deux chiffres.
+func stripDigitSeparators(s string) (string, error) {
+	if !strings.Contains(s, "_") {
+		return s, nil
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	for i := 0; i < len(s); i++ {
+		if s[i] == '_' && (i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1])) {
+			return "", fmt.Errorf("indice invalide %q : « _ » doit séparer deux chiffres", s)
+		}
+	}
+	return strings.ReplaceAll(s, "_", ""), nil
+}
+
+// indexFlag est la valeur de l'option -n, lue avec parseIndex.
+type indexFlag struct{ n *int }
+
+// String retourne la valeur courante de l'option.
+func (f indexFlag) String() string {
+	if f.n == nil {
+		return "0"
+	}
+	return strconv.Itoa(*f.n)
+}
+
+// Set interprète la valeur passée à l'option.
+func (f indexFlag) Set(s string) error {
+	n, err := parseIndex(s)
+	if err != nil {
+		return err
+	}
+	*f.n = n
+	return nil
+}
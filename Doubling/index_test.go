@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"42", 42, false},
+		{"-7", -7, false},
+		{"1_000_000", 1_000_000, false},
+		{"1e6", 1_000_000, false},
+		{"1E6", 1_000_000, false},
+		{"2.5e5", 250_000, false},
+		{"1.5E6", 1_500_000, false},
+		{"1_0e2", 1000, false},
+		{"1e18", 1_000_000_000_000_000_000, false},
+		{"12e-1", 0, true}, // 1,2 n'est pas entier
+		{"120e-1", 12, false},
+		{"1.5e0", 0, true},
+		{"1.23", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"_1", 0, true},
+		{"1_", 0, true},
+		{"1__0", 0, true},
+		{"1_e6", 0, true},
+		{"e6", 0, true},
+		{"1e", 0, true},
+		{"1e+", 0, true},
+		{"1.2.3e4", 0, true},
+		{"1/2e3", 0, true},
+		{"+1e3", 0, true},
+		{"1e41", 0, true},
+		{"1e-41", 0, true},
+		{"1e19", 0, true}, // Dépasse int64
+		{"9223372036854775808", 0, true},
+		{"0x1.8p3", 0, true}, // Syntaxes de big.Rat autres que décimales
+		{"1.p3", 0, true},
+		{"0b1.1", 0, true},
+		{".", 0, true},
+		{"-.5e1", -5, false},
+		{"5.e1", 50, false},
+	}
+	for _, tt := range tests {
+		got, err := parseIndex(tt.in)
+		if (err != nil) != tt.wantErr || (err == nil && got != tt.want) {
+			t.Errorf("parseIndex(%q) = %d, %v ; attendu %d (erreur : %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// FuzzParseIndex vérifie que parseIndex ne panique jamais, qu'il coïncide avec
+// strconv.Atoi sur les entiers décimaux ordinaires et que toute valeur
+// acceptée désigne exactement le nombre écrit.
+func FuzzParseIndex(f *testing.F) {
+	for _, seed := range []string{"0", "42", "-7", "1_000_000", "1e6", "2.5e5", "1.5e0", "1e41", "1_", "9e18", "1e-3"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := parseIndex(s)
+		if want, atoiErr := strconv.Atoi(s); atoiErr == nil {
+			if err != nil || n != want {
+				t.Fatalf("parseIndex(%q) = %d, %v ; strconv.Atoi donne %d", s, n, err, want)
+			}
+		}
+		if err != nil {
+			return
+		}
+		r, ok := new(big.Rat).SetString(strings.ReplaceAll(s, "_", ""))
+		if !ok || !r.IsInt() || r.Num().Cmp(big.NewInt(int64(n))) != 0 {
+			t.Fatalf("parseIndex(%q) = %d, qui n'est pas la valeur écrite", s, n)
+		}
+		if back, err := parseIndex(strconv.Itoa(n)); err != nil || back != n {
+			t.Fatalf("parseIndex(%q) = %d, mais parseIndex(%q) = %d, %v", s, n, strconv.Itoa(n), back, err)
+		}
+	})
+}
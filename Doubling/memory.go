@@ -0,0 +1,124 @@
+// =============================================================================
+// Garde-fou mémoire : refuse un calcul dont l'empreinte estimée dépasse le
+// budget fixé par -max-memory ou la mémoire disponible du système.
+//
+// F(n) occupe environ n·log2(φ) bits. Le pic mesuré du doublement parallélisé
+// (temporaires, produits de taille double, conversion décimale de l'affichage
+// et marge du ramasse-miettes) est de l'ordre de 40 fois cette taille, plus une
+// base fixe pour le processus.
+// =============================================================================
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	memoryBase   = 16 << 20 // Empreinte fixe du processus, en octets
+	memoryFactor = 40       // Pic mémoire rapporté à la taille de F(n)
+)
+
+// estimateMemory retourne une estimation du pic mémoire, en octets, du calcul
+// de F(n).
+func estimateMemory(n int) uint64 {
+	if n <= 0 {
+		return memoryBase
+	}
+	resultBytes := float64(n) * math.Log2(math.Phi) / 8
+	return memoryBase + uint64(memoryFactor*resultBytes)
+}
+
+// byteUnits associe les suffixes reconnus par parseByteSize à leur multiplicateur.
+var byteUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// parseByteSize interprète une taille telle que "2GiB", "512MB" ou "1048576".
+// Les suffixes en iB sont binaires (1024), les autres décimaux (1000).
+func parseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	unit, ok := byteUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("unité de taille inconnue dans %q (B, KB, MB, GB, TB, KiB, MiB, GiB, TiB)", s)
+	}
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("taille invalide : %q", s)
+	}
+	size := value * float64(unit)
+	if size > math.MaxUint64 {
+		return 0, fmt.Errorf("taille trop grande : %q", s)
+	}
+	return uint64(size), nil
+}
+
+// availableMemory retourne la mémoire disponible du système (MemAvailable de
+// /proc/meminfo). ok est faux lorsque l'information n'est pas accessible.
+func availableMemory() (bytes uint64, ok bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			return kb << 10, true
+		}
+	}
+	return 0, false
+}
+
+// checkMemoryBudget vérifie que le calcul de F(n) tient dans budget octets et
+// dans la mémoire disponible du système.
+func checkMemoryBudget(n int, budget uint64) error {
+	need := estimateMemory(n)
+	if need > budget {
+		return fmt.Errorf("le calcul de F(%d) nécessite environ %s, au-delà du budget -max-memory de %s",
+			n, formatBytes(need), formatBytes(budget))
+	}
+	if avail, ok := availableMemory(); ok && need > avail {
+		return fmt.Errorf("le calcul de F(%d) nécessite environ %s, au-delà de la mémoire disponible (%s)",
+			n, formatBytes(need), formatBytes(avail))
+	}
+	return nil
+}
+
+// formatBytes rend une taille en octets avec l'unité binaire la plus adaptée.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d o", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cio", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMemoryBudget(t *testing.T) {
+	const n = 100_000_000 // F(10⁸) : environ 350 Mo estimés
+	err := checkMemoryBudget(n, 1<<20)
+	if err == nil || !strings.Contains(err.Error(), "-max-memory") {
+		t.Errorf("budget de 1 Mio pour F(%d) : %v ; attendu un refus", n, err)
+	}
+	// Un budget généreux laisse passer un calcul modeste, que la mémoire
+	// disponible du système suffit à couvrir.
+	if err := checkMemoryBudget(1_000_000, 1<<40); err != nil {
+		t.Errorf("budget de 1 Tio pour F(10⁶) : %v", err)
+	}
+}
+
+func TestEstimateMemoryGrowsWithN(t *testing.T) {
+	if got := estimateMemory(0); got != memoryBase {
+		t.Errorf("estimateMemory(0) = %d ; attendu la base %d", got, memoryBase)
+	}
+	small, large := estimateMemory(1_000_000), estimateMemory(100_000_000)
+	if small <= memoryBase || large <= small {
+		t.Errorf("estimations non croissantes : %d, %d", small, large)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"1048576", 1 << 20},
+		{"512MiB", 512 << 20},
+		{"2GiB", 2 << 30},
+		{"1.5 kb", 1500},
+		{"3TB", 3e12},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, %v ; attendu %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "0", "-1GiB", "12XB", "GiB"} {
+		if _, err := parseByteSize(in); err == nil {
+			t.Errorf("parseByteSize(%q) : erreur attendue", in)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for b, want := range map[uint64]string{512: "512 o", 1536: "1.5 Kio", 2 << 30: "2.0 Gio"} {
+		if got := formatBytes(b); got != want {
+			t.Errorf("formatBytes(%d) = %q ; attendu %q", b, got, want)
+		}
+	}
+}
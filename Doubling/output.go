@@ -0,0 +1,194 @@
+// =============================================================================
+// Écriture du résultat complet dans un fichier.
+//
+// Le format de sortie est choisi explicitement via -format, ou à défaut déduit
+// de l'extension du fichier passé à -out :
+//   - .json : objet JSON {"n": ..., "result": "..."}
+//   - .txt  : représentation décimale
+//   - .bin  : octets big-endian de la valeur absolue (big.Int.Bytes)
+// Une extension inconnue retombe sur le format texte. Le format "bits", qui
+// écrit le développement binaire sous forme de caractères '0'/'1', ne peut être
+// choisi qu'explicitement ; sans -out, il est écrit sur la sortie standard.
+//
+// Un chemin se terminant par .gz (par exemple resultat.txt.gz), ou l'option
+// -compress, produit un fichier compressé avec gzip ; le format est alors déduit
+// de l'extension qui précède .gz.
+// =============================================================================
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats de sortie reconnus.
+const (
+	FormatJSON   = "json"
+	FormatText   = "text"
+	FormatBinary = "binary"
+	FormatBits   = "bits"
+)
+
+// extensionFormats associe chaque extension de fichier reconnue à son format.
+var extensionFormats = map[string]string{
+	".json": FormatJSON,
+	".txt":  FormatText,
+	".bin":  FormatBinary,
+}
+
+// gzipExtension est l'extension qui active la compression du fichier de sortie.
+const gzipExtension = ".gz"
+
+// isCompressedOutput indique si le fichier de sortie doit être compressé : soit
+// explicitement (-compress), soit parce que son chemin se termine par .gz.
+func isCompressedOutput(path string, compress bool) bool {
+	return compress || strings.EqualFold(filepath.Ext(path), gzipExtension)
+}
+
+// formatFromExtension déduit le format de sortie à partir de l'extension du
+// chemin, en ignorant un suffixe .gz. Une extension inconnue (ou absente) donne
+// le format texte.
+func formatFromExtension(path string) string {
+	if strings.EqualFold(filepath.Ext(path), gzipExtension) {
+		path = path[:len(path)-len(gzipExtension)]
+	}
+	if format, ok := extensionFormats[strings.ToLower(filepath.Ext(path))]; ok {
+		return format
+	}
+	return FormatText
+}
+
+// resolveFormat retourne le format effectif du fichier de sortie : le format
+// explicite s'il est fourni, sinon celui déduit de l'extension.
+func resolveFormat(config Configuration) (string, error) {
+	switch config.Format {
+	case "":
+		return formatFromExtension(config.OutputFile), nil
+	case FormatJSON, FormatText, FormatBinary, FormatBits:
+		return config.Format, nil
+	default:
+		return "", fmt.Errorf("format de sortie inconnu : %q (attendu : json, text, binary ou bits)", config.Format)
+	}
+}
+
+// resultRecord regroupe le résultat et les analyses optionnelles à écrire.
+type resultRecord struct {
+	N              int             // Indice calculé
+	Value          *big.Int        // Fibonacci(n)
+	DigitHistogram *DigitHistogram // Histogramme des chiffres (optionnel)
+	Pretty         bool            // Indente la sortie JSON (-json-pretty)
+}
+
+// jsonIndent est l'indentation de la sortie JSON avec -json-pretty.
+const jsonIndent = "  "
+
+// newJSONEncoder retourne un encodeur JSON vers w, indenté si pretty est vrai.
+func newJSONEncoder(w io.Writer, pretty bool) *json.Encoder {
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", jsonIndent)
+	}
+	return enc
+}
+
+// fileResult est la représentation JSON du résultat écrit sur disque. Les champs
+// sont toujours émis dans l'ordre de la structure (n, result, digitHistogram),
+// ce qui rend les sorties de deux exécutions directement comparables.
+type fileResult struct {
+	N              int             `json:"n"`                        // Indice calculé
+	Result         string          `json:"result"`                   // Valeur calculée en décimal : F(n), L(n) ou somme
+	DigitHistogram *DigitHistogram `json:"digitHistogram,omitempty"` // Occurrences des chiffres 0 à 9
+}
+
+// writeResultFile écrit le résultat dans le fichier path selon le format demandé,
+// compressé avec gzip si compress est vrai. Le fichier est fermé dans tous les cas.
+func writeResultFile(path, format string, rec resultRecord, compress bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var zw *gzip.Writer
+	var dst io.Writer = f
+	if compress {
+		zw = gzip.NewWriter(f)
+		dst = zw
+	}
+
+	w := bufio.NewWriter(dst)
+	err = writeResult(w, format, rec)
+	if err == nil {
+		err = w.Flush()
+	}
+	if zw != nil {
+		// Close écrit la fin du flux gzip ; il doit précéder la fermeture du fichier.
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeResult écrit le résultat sur w selon le format demandé.
+func writeResult(w io.Writer, format string, rec resultRecord) error {
+	fib := rec.Value
+	switch format {
+	case FormatJSON:
+		out := fileResult{N: rec.N, Result: fib.String(), DigitHistogram: rec.DigitHistogram}
+		if err := newJSONEncoder(w, rec.Pretty).Encode(out); err != nil {
+			return fmt.Errorf("encodage JSON : %w", err)
+		}
+		return nil
+	case FormatBinary:
+		_, err := w.Write(fib.Bytes())
+		return err
+	case FormatBits:
+		if err := writeBits(w, fib); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	default:
+		// Conversion par blocs : la chaîne décimale complète n'est jamais matérialisée.
+		if err := writeDecimal(w, fib); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+}
+
+// writeBits écrit le développement binaire de v (du bit de poids fort au bit de
+// poids faible) sous forme de caractères '0'/'1', par blocs, sans construire la
+// chaîne complète en mémoire. Zéro s'écrit "0".
+func writeBits(w io.Writer, v *big.Int) error {
+	bitLen := v.BitLen()
+	if bitLen == 0 {
+		_, err := io.WriteString(w, "0")
+		return err
+	}
+
+	const chunkSize = 64 * 1024
+	buf := make([]byte, 0, chunkSize)
+	for i := bitLen - 1; i >= 0; i-- {
+		buf = append(buf, byte('0'+v.Bit(i)))
+		if len(buf) == chunkSize {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
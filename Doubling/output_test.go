@@ -0,0 +1,222 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatFromExtension(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"fib.json", FormatJSON},
+		{"fib.JSON", FormatJSON},
+		{"fib.txt", FormatText},
+		{"fib.bin", FormatBinary},
+		{"fib.json.gz", FormatJSON},
+		{"fib.bin.GZ", FormatBinary},
+		{"fib.gz", FormatText},
+		{"fib.csv", FormatText},
+		{"fib", FormatText},
+		{"", FormatText},
+		{"dir.json/fib", FormatText},
+	}
+	for _, tt := range tests {
+		if got := formatFromExtension(tt.path); got != tt.want {
+			t.Errorf("formatFromExtension(%q) = %q, attendu %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		format, out string
+		want        string
+		wantErr     bool
+	}{
+		{"", "fib.json", FormatJSON, false},
+		{"", "", FormatText, false},
+		{FormatBits, "fib.json", FormatBits, false}, // Le format explicite prime sur l'extension
+		{FormatBinary, "", FormatBinary, false},
+		{"xml", "fib.json", "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveFormat(Configuration{Format: tt.format, OutputFile: tt.out})
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveFormat(-format %q, -out %q) = %q, %v ; attendu %q (erreur : %v)",
+				tt.format, tt.out, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestIsCompressedOutput(t *testing.T) {
+	tests := []struct {
+		path     string
+		compress bool
+		want     bool
+	}{
+		{"fib.txt", false, false},
+		{"fib.txt", true, true},
+		{"fib.txt.gz", false, true},
+		{"fib.txt.GZ", false, true},
+	}
+	for _, tt := range tests {
+		if got := isCompressedOutput(tt.path, tt.compress); got != tt.want {
+			t.Errorf("isCompressedOutput(%q, %v) = %v, attendu %v", tt.path, tt.compress, got, tt.want)
+		}
+	}
+}
+
+func TestWriteResultFileRoundTrip(t *testing.T) {
+	v, _ := fibDoublingPair(500)
+	rec := resultRecord{N: 500, Value: v}
+	decodeJSON := func(data []byte) (*big.Int, error) {
+		var out fileResult
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, err
+		}
+		return parseDecimal(out.Result)
+	}
+	tests := []struct {
+		name   string
+		decode func(data []byte) (*big.Int, error)
+	}{
+		{"fib.txt", func(data []byte) (*big.Int, error) {
+			return parseDecimal(strings.TrimSpace(string(data)))
+		}},
+		{"fib.json", decodeJSON},
+		{"fib.bin", func(data []byte) (*big.Int, error) { return new(big.Int).SetBytes(data), nil }},
+		{"fib.json.gz", decodeJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			compress := isCompressedOutput(path, false)
+			if err := writeResultFile(path, formatFromExtension(path), rec, compress); err != nil {
+				t.Fatalf("writeResultFile : %v", err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var r io.Reader = f
+			if compress {
+				zr, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatalf("gzip : %v", err)
+				}
+				r = zr
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.decode(data)
+			if err != nil {
+				t.Fatalf("relecture : %v", err)
+			}
+			if got.Cmp(v) != 0 {
+				t.Errorf("valeur relue différente de F(500)")
+			}
+		})
+	}
+}
+
+// parseDecimal interprète s comme un entier décimal.
+func parseDecimal(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("entier décimal invalide : %q", s)
+	}
+	return v, nil
+}
+
+func TestWriteBits(t *testing.T) {
+	f10, _ := fibDoublingPair(10)
+	var buf strings.Builder
+	if err := writeBits(&buf, f10); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "110111" || buf.Len() != f10.BitLen() {
+		t.Errorf("writeBits(F(10)) = %q ; attendu \"110111\" (%d bits)", buf.String(), f10.BitLen())
+	}
+
+	// Au-delà d'un bloc de 64 Kio, l'écriture par blocs reste identique à Text(2).
+	large, _ := fibDoublingPair(200_000)
+	buf.Reset()
+	if err := writeBits(&buf, large); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != large.BitLen() || buf.String() != large.Text(2) {
+		t.Errorf("writeBits(F(200000)) : %d caractères, attendu Text(2) de %d bits", buf.Len(), large.BitLen())
+	}
+
+	buf.Reset()
+	if err := writeBits(&buf, new(big.Int)); err != nil || buf.String() != "0" {
+		t.Errorf("writeBits(0) = %q, %v ; attendu \"0\"", buf.String(), err)
+	}
+}
+
+func TestWriteResultBits(t *testing.T) {
+	f10, _ := fibDoublingPair(10)
+	var buf strings.Builder
+	if err := writeResult(&buf, FormatBits, resultRecord{N: 10, Value: f10}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "110111\n" {
+		t.Errorf("writeResult(bits, F(10)) = %q ; attendu \"110111\\n\"", buf.String())
+	}
+}
+
+func TestValidateRejectsBitsWithBase(t *testing.T) {
+	for _, set := range []func(*Configuration){
+		func(c *Configuration) { c.Binary = true },
+		func(c *Configuration) { c.Base = 16 },
+	} {
+		config := DefaultConfig()
+		config.Format = FormatBits
+		set(&config)
+		if err := config.Validate(); err == nil {
+			t.Errorf("Validate(-format bits, -binary %v, -base %d) : erreur attendue", config.Binary, config.Base)
+		}
+	}
+}
+
+func TestWriteResultPrettyJSON(t *testing.T) {
+	f10, _ := fibDoublingPair(10)
+	h := DigitHistogram{5: 2}
+	var buf strings.Builder
+	rec := resultRecord{N: 10, Value: f10, DigitHistogram: &h, Pretty: true}
+	if err := writeResult(&buf, FormatJSON, rec); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n" +
+		"  \"n\": 10,\n" +
+		"  \"result\": \"55\",\n" +
+		"  \"digitHistogram\": [\n" +
+		"    0,\n    0,\n    0,\n    0,\n    0,\n    2,\n    0,\n    0,\n    0,\n    0\n" +
+		"  ]\n" +
+		"}\n"
+	if buf.String() != want {
+		t.Errorf("sortie indentée :\n%s\nattendu :\n%s", buf.String(), want)
+	}
+
+	// Sans -json-pretty : une seule ligne, mêmes champs dans le même ordre.
+	buf.Reset()
+	rec.Pretty = false
+	if err := writeResult(&buf, FormatJSON, rec); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"n":10,"result":"55","digitHistogram":[0,0,0,0,0,2,0,0,0,0]}` + "\n"; buf.String() != want {
+		t.Errorf("sortie compacte %q ; attendu %q", buf.String(), want)
+	}
+}
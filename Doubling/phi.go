@@ -0,0 +1,45 @@
+// =============================================================================
+// Calcul du nombre d'or φ avec une précision arbitraire.
+//
+// Le rapport F(k+1)/F(k) converge vers φ = (1 + √5) / 2 avec une erreur de
+// l'ordre de φ^(-2k). Pour obtenir N décimales exactes, il suffit donc de
+// choisir k tel que 2k·log10(φ) dépasse N (plus une marge de sécurité), puis
+// d'effectuer la division en big.Float avec une précision suffisante.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// maxPhiDigits borne le nombre de décimales demandées pour φ.
+const maxPhiDigits = 1000000
+
+// phiGuardDigits est la marge de décimales ajoutée pour absorber l'erreur de
+// convergence et l'arrondi de la division.
+const phiGuardDigits = 10
+
+// computePhi retourne φ tronqué à digits décimales, sous forme de chaîne.
+func computePhi(digits int) (string, error) {
+	if digits <= 0 || digits > maxPhiDigits {
+		return "", fmt.Errorf("le nombre de décimales de φ doit être compris entre 1 et %d (reçu %d)", maxPhiDigits, digits)
+	}
+
+	// Choix de k : l'erreur |F(k+1)/F(k) - φ| ≈ φ^(-2k) doit rester sous 10^-(digits+garde).
+	k := int(math.Ceil(float64(digits+phiGuardDigits)/(2*math.Log10(math.Phi)))) + 1
+	fk, fk1 := fibDoublingPair(k)
+
+	// Précision binaire suffisante pour représenter digits+garde décimales.
+	prec := uint(math.Ceil(float64(digits+phiGuardDigits)*math.Log2(10))) + 64
+	ratio := new(big.Float).SetPrec(prec).Quo(
+		new(big.Float).SetPrec(prec).SetInt(fk1),
+		new(big.Float).SetPrec(prec).SetInt(fk),
+	)
+
+	// Rendu avec la marge puis troncature, afin de ne pas arrondir la dernière décimale.
+	text := ratio.Text('f', digits+phiGuardDigits)
+	return text[:len(text)-phiGuardDigits], nil
+}
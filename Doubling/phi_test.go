@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// phiReference est φ avec 60 décimales.
+const phiReference = "1.618033988749894848204586834365638117720309179805762862135448"
+
+func TestComputePhi(t *testing.T) {
+	for _, digits := range []int{1, 5, 10, 30, 60} {
+		got, err := computePhi(digits)
+		if err != nil {
+			t.Fatalf("computePhi(%d) : %v", digits, err)
+		}
+		if want := phiReference[:2+digits]; got != want {
+			t.Errorf("computePhi(%d) = %s, attendu %s", digits, got, want)
+		}
+	}
+}
+
+func TestComputePhiRejectsOutOfRange(t *testing.T) {
+	for _, digits := range []int{-1, 0, maxPhiDigits + 1} {
+		if _, err := computePhi(digits); err == nil {
+			t.Errorf("computePhi(%d) : erreur attendue", digits)
+		}
+	}
+}
+
+func TestRunUntilDoneStopsOnDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	defer close(release)
+	_, err := runUntilDone(ctx, func() (string, error) {
+		<-release
+		return "trop tard", nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("erreur = %v, attendu %v", err, context.DeadlineExceeded)
+	}
+
+	got, err := runUntilDone(context.Background(), func() (string, error) { return computePhi(3) })
+	if err != nil || !strings.HasPrefix(got, "1.618") {
+		t.Fatalf("runUntilDone = %q, %v", got, err)
+	}
+}
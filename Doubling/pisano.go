@@ -0,0 +1,71 @@
+// =============================================================================
+// Période de Pisano.
+//
+// La suite F(n) mod m est périodique ; sa période π(m) est la période de
+// Pisano. Elle est atteinte au plus petit k > 0 tel que F(k) ≡ 0 et
+// F(k+1) ≡ 1 (mod m), et l'on sait que π(m) ≤ 6m. Le couple (F(k), F(k+1))
+// est avancé d'un rang à chaque itération en arithmétique modulaire sur
+// uint64, puis la période trouvée est vérifiée par le doublement modulaire.
+// =============================================================================
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// pisanoCheckInterval est le nombre d'itérations entre deux vérifications du contexte.
+const pisanoCheckInterval = 1 << 20
+
+// addMod retourne (a + b) mod m pour a, b < m, sans débordement.
+func addMod(a, b, m uint64) uint64 {
+	if a >= m-b {
+		return a - (m - b)
+	}
+	return a + b
+}
+
+// PisanoPeriod retourne la période de Pisano π(m) pour m ≥ 1.
+// Le calcul s'interrompt si le contexte est annulé.
+func PisanoPeriod(ctx context.Context, m uint64) (uint64, error) {
+	if m == 0 {
+		return 0, fmt.Errorf("le module doit être strictement positif")
+	}
+	if m == 1 {
+		return 1, nil // Toute la suite est nulle modulo 1
+	}
+
+	// (a, b) = (F(k), F(k+1)) mod m, en partant de k = 0.
+	var a, b uint64 = 0, 1
+	limit := 6 * m
+	if limit/6 != m {
+		limit = ^uint64(0) // Débordement : pas de borne utile
+	}
+	for k := uint64(1); k <= limit; k++ {
+		a, b = b, addMod(a, b, m)
+		if a == 0 && b == 1 {
+			if err := verifyPisanoPeriod(k, m); err != nil {
+				return 0, err
+			}
+			return k, nil
+		}
+		if k%pisanoCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return 0, fmt.Errorf("aucune période trouvée pour m = %d", m)
+}
+
+// verifyPisanoPeriod contrôle, par le doublement modulaire, que F(k) ≡ 0 et
+// F(k+1) ≡ 1 (mod m).
+func verifyPisanoPeriod(k, m uint64) error {
+	fk, fk1 := fibDoublingPairMod(int(k), new(big.Int).SetUint64(m))
+	if fk.Sign() != 0 || fk1.Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("incohérence interne : π(%d) = %d non confirmée par le doublement", m, k)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPisanoPeriodKnownValues(t *testing.T) {
+	tests := []struct{ m, want uint64 }{
+		{1, 1},
+		{2, 3},
+		{3, 8},
+		{5, 20},
+		{10, 60},
+		{1000, 1500},
+	}
+	for _, tt := range tests {
+		got, err := PisanoPeriod(context.Background(), tt.m)
+		if err != nil {
+			t.Errorf("PisanoPeriod(%d) : %v", tt.m, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("π(%d) = %d ; attendu %d", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestPisanoPeriodRejectsZero(t *testing.T) {
+	if _, err := PisanoPeriod(context.Background(), 0); err == nil {
+		t.Error("PisanoPeriod(0) : erreur attendue")
+	}
+}
+
+func TestPisanoPeriodHonorsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// π(10⁹ + 7) dépasse largement pisanoCheckInterval : le contexte est
+	// consulté avant que la période soit atteinte.
+	if _, err := PisanoPeriod(ctx, 1_000_000_007); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v ; attendu context.Canceled", err)
+	}
+}
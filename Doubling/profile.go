@@ -0,0 +1,111 @@
+// =============================================================================
+// Profilage de l'exécution (pprof et trace d'exécution).
+//
+// -cpuprofile enregistre un profil processeur pendant toute l'exécution,
+// -memprofile écrit un profil du tas à la fin et -trace une trace d'exécution
+// lisible par « go tool trace ». Les fichiers doivent être finalisés même
+// lorsque le programme s'arrête sur une erreur : log.Fatalf ne déroulant pas
+// les defer, les arrêts sur erreur passent par fatalf, qui finalise d'abord
+// les profils.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+)
+
+// profiler détient les profils en cours d'enregistrement. Un *profiler nil est
+// valide et ne fait rien.
+type profiler struct {
+	cpu     *os.File // Profil processeur (nil si absent)
+	trace   *os.File // Trace d'exécution (nil si absente)
+	memPath string   // Fichier du profil du tas écrit à l'arrêt (vide si absent)
+	once    sync.Once
+}
+
+// activeProfiler est le profilage en cours, finalisé par fatalf.
+var activeProfiler *profiler
+
+// startProfiling démarre les profils demandés. Il retourne nil si aucun ne l'est.
+func startProfiling(cpuPath, memPath, tracePath string) (p *profiler, err error) {
+	if cpuPath == "" && memPath == "" && tracePath == "" {
+		return nil, nil
+	}
+	p = &profiler{memPath: memPath}
+	defer func() {
+		if err != nil {
+			p.Stop() // Ne pas laisser un profil à moitié démarré
+		}
+	}()
+	if cpuPath != "" {
+		if p.cpu, err = os.Create(cpuPath); err != nil {
+			return nil, fmt.Errorf("profil processeur : %w", err)
+		}
+		if err = pprof.StartCPUProfile(p.cpu); err != nil {
+			p.cpu.Close()
+			p.cpu = nil
+			return nil, fmt.Errorf("profil processeur : %w", err)
+		}
+	}
+	if tracePath != "" {
+		if p.trace, err = os.Create(tracePath); err != nil {
+			return nil, fmt.Errorf("trace d'exécution : %w", err)
+		}
+		if err = trace.Start(p.trace); err != nil {
+			p.trace.Close()
+			p.trace = nil
+			return nil, fmt.Errorf("trace d'exécution : %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Stop arrête les profils et écrit le profil du tas. Seul le premier appel a un effet.
+func (p *profiler) Stop() {
+	if p == nil {
+		return
+	}
+	p.once.Do(func() {
+		if p.cpu != nil {
+			pprof.StopCPUProfile()
+			p.cpu.Close()
+		}
+		if p.trace != nil {
+			trace.Stop()
+			p.trace.Close()
+		}
+		if p.memPath != "" {
+			if err := writeHeapProfile(p.memPath); err != nil {
+				log.Printf("Profil mémoire : %v", err)
+			}
+		}
+	})
+}
+
+// writeHeapProfile écrit le profil du tas dans path, après un passage du
+// ramasse-miettes pour que les statistiques soient à jour.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// fatalf finalise les profils en cours puis arrête le programme comme log.Fatalf.
+func fatalf(format string, args ...any) {
+	activeProfiler.Stop()
+	log.Fatalf(format, args...)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// checkNonEmpty échoue si l'un des fichiers est absent ou vide.
+func checkNonEmpty(t *testing.T, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Errorf("%s : %v", filepath.Base(path), err)
+		} else if info.Size() == 0 {
+			t.Errorf("%s est vide", filepath.Base(path))
+		}
+	}
+}
+
+// TestProfilingWritesFiles enregistre les profils dans un processus fils : le
+// profil du tas de ce dernier reste petit, alors que celui du binaire de test
+// complet recense les allocations de tous les tests déjà exécutés.
+func TestProfilingWritesFiles(t *testing.T) {
+	dir := os.Getenv("FIB_PROFILE_DIR")
+	child := dir != ""
+	if !child {
+		dir = t.TempDir()
+	}
+	cpu, mem, tr := filepath.Join(dir, "cpu.prof"), filepath.Join(dir, "mem.prof"), filepath.Join(dir, "trace.out")
+	if child {
+		p, err := startProfiling(cpu, mem, tr)
+		if err != nil {
+			t.Fatalf("startProfiling : %v", err)
+		}
+		fibDoublingPair(200_000)
+		p.Stop()
+		p.Stop() // Sans effet
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestProfilingWritesFiles$")
+	cmd.Env = append(os.Environ(), "FIB_PROFILE_DIR="+dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("processus fils : %v\n%s", err, out)
+	}
+	checkNonEmpty(t, cpu, mem, tr)
+}
+
+func TestProfilingDisabled(t *testing.T) {
+	p, err := startProfiling("", "", "")
+	if p != nil || err != nil {
+		t.Fatalf("startProfiling sans fichier = %v, %v ; attendu nil, nil", p, err)
+	}
+	p.Stop() // Un *profiler nil est valide
+}
+
+// TestFatalfFinalizesCPUProfile vérifie, dans un processus fils, qu'un arrêt
+// sur erreur par fatalf laisse un profil processeur complet.
+func TestFatalfFinalizesCPUProfile(t *testing.T) {
+	if path := os.Getenv("FIB_PROFILE_CHILD"); path != "" {
+		var err error
+		if activeProfiler, err = startProfiling(path, "", ""); err != nil {
+			t.Fatal(err)
+		}
+		fibDoublingPair(200_000)
+		fatalf("arrêt simulé")
+		return
+	}
+	cpu := filepath.Join(t.TempDir(), "cpu.prof")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalfFinalizesCPUProfile$")
+	cmd.Env = append(os.Environ(), "FIB_PROFILE_CHILD="+cpu)
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() == 0 {
+		t.Fatalf("processus fils : %v, attendu un code de sortie non nul\n%s", err, out)
+	}
+	checkNonEmpty(t, cpu)
+}
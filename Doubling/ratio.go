@@ -0,0 +1,47 @@
+// =============================================================================
+// Convergent F(n)/F(n-1) du nombre d'or.
+//
+// Le quotient de deux termes consécutifs converge vers φ avec une erreur de
+// l'ordre de φ^(-2n). Les deux termes sont obtenus par un seul appel au
+// doublement, qui fournit le couple (F(n-1), F(n)).
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ratioFractionDigits est la taille au-delà de laquelle la fraction F(n)/F(n-1)
+// est affichée en notation scientifique plutôt qu'en toutes lettres.
+const ratioFractionDigits = 60
+
+// fibRatio retourne F(n), F(n-1) et le développement décimal de F(n)/F(n-1)
+// tronqué à digits décimales. n doit valoir au moins 2 (F(0) = 0).
+func fibRatio(n, digits int) (num, den *big.Int, decimal string, err error) {
+	if n < 2 {
+		return nil, nil, "", fmt.Errorf("le rapport F(n)/F(n-1) nécessite n ≥ 2 (reçu %d)", n)
+	}
+	if digits < 0 {
+		return nil, nil, "", fmt.Errorf("le nombre de décimales doit être positif ou nul (reçu %d)", digits)
+	}
+	den, num = fibDoublingPair(n - 1)
+
+	// Troncature exacte : ⌊F(n)·10^digits / F(n-1)⌋, puis insertion de la virgule.
+	scaled := new(big.Int).Mul(num, pow10(digits))
+	text := scaled.Quo(scaled, den).String()
+	if digits == 0 {
+		return num, den, text, nil
+	}
+	return num, den, text[:len(text)-digits] + "." + text[len(text)-digits:], nil
+}
+
+// formatRatio rend la fraction num/den = F(n)/F(n-1), en notation scientifique
+// si ses termes sont trop longs pour être lisibles.
+func formatRatio(n int, num, den *big.Int, precision int) string {
+	if DigitCount(uint64(n)) > ratioFractionDigits {
+		return formatBigIntSup(num, precision) + " / " + formatBigIntSup(den, precision)
+	}
+	return num.String() + "/" + den.String()
+}
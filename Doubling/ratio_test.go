@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestFibRatio(t *testing.T) {
+	tests := []struct {
+		n, digits int
+		num, den  string
+		decimal   string
+	}{
+		{2, 3, "1", "1", "1.000"},
+		{10, 0, "55", "34", "1"},
+		{10, 10, "55", "34", "1.6176470588"},
+		{40, 15, "102334155", "63245986", "1.618033988749894"},
+	}
+	for _, tt := range tests {
+		num, den, decimal, err := fibRatio(tt.n, tt.digits)
+		if err != nil {
+			t.Fatalf("fibRatio(%d, %d) : %v", tt.n, tt.digits, err)
+		}
+		if num.String() != tt.num || den.String() != tt.den || decimal != tt.decimal {
+			t.Errorf("fibRatio(%d, %d) = %s/%s ≈ %s, attendu %s/%s ≈ %s",
+				tt.n, tt.digits, num, den, decimal, tt.num, tt.den, tt.decimal)
+		}
+	}
+}
+
+func TestFibRatioRejectsInvalidArguments(t *testing.T) {
+	for _, tt := range []struct{ n, digits int }{{0, 5}, {1, 5}, {10, -1}} {
+		if _, _, _, err := fibRatio(tt.n, tt.digits); err == nil {
+			t.Errorf("fibRatio(%d, %d) : erreur attendue", tt.n, tt.digits)
+		}
+	}
+}
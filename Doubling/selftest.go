@@ -0,0 +1,106 @@
+// =============================================================================
+// Auto-test des calculs après compilation.
+//
+// -selftest compare chaque chemin de calcul du programme à un oracle obtenu par
+// additions successives (F(i+2) = F(i+1) + F(i)), trop lent pour de grands n
+// mais sans multiplication ni identité susceptible de masquer une erreur. Le
+// programme se termine en erreur au premier écart constaté sur l'ensemble des
+// indices testés.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// selfTestIndices sont les indices vérifiés par -selftest : cas de base,
+// F(93) (plus grand terme tenant sur 64 bits) et quelques grands indices.
+var selfTestIndices = []int{0, 1, 2, 10, 93, 1000, 10000}
+
+// oracleValues sont les valeurs de référence associées à un indice n.
+type oracleValues struct {
+	F, FPrev, FNext *big.Int // F(n), F(n-1) (F(1) pour n = 0), F(n+1)
+	Sum             *big.Int // F(0) + … + F(n-1)
+}
+
+// oracle calcule les valeurs de référence de n par additions successives.
+func oracle(n int) oracleValues {
+	prev, cur := big.NewInt(1), big.NewInt(0) // F(-1), F(0)
+	sum := new(big.Int)
+	for range n {
+		sum.Add(sum, cur)
+		prev.Add(prev, cur)
+		prev, cur = cur, prev
+	}
+	return oracleValues{F: cur, FPrev: prev, FNext: new(big.Int).Add(prev, cur), Sum: sum}
+}
+
+// selfTestCase décrit un chemin de calcul et la valeur de référence attendue.
+type selfTestCase struct {
+	Name     string
+	Compute  func(n int) (*big.Int, error)
+	Expected func(o oracleValues) *big.Int
+}
+
+// selfTestCases retourne les chemins de calcul vérifiés par -selftest.
+func selfTestCases() []selfTestCase {
+	fc := NewFibCalculator()
+	fib := func(o oracleValues) *big.Int { return o.F }
+	return []selfTestCase{
+		{Name: "Doublement", Compute: fc.Calculate, Expected: fib},
+		{Name: "Doublement modulaire", Compute: func(n int) (*big.Int, error) {
+			// F(n) < 2^n : le module 2^(n+1) laisse le résultat inchangé.
+			fn, _ := fibDoublingPairMod(n, new(big.Int).Lsh(big.NewInt(1), uint(n+1)))
+			return fn, nil
+		}, Expected: fib},
+		{Name: "Lucas", Compute: fc.CalculateLucas, Expected: func(o oracleValues) *big.Int {
+			return new(big.Int).Add(o.FPrev, o.FNext) // L(n) = F(n-1) + F(n+1)
+		}},
+		{Name: "Somme", Compute: fc.PrefixSum, Expected: func(o oracleValues) *big.Int { return o.Sum }},
+	}
+}
+
+// runSelfTest vérifie chaque cas pour chaque indice et écrit un résumé sur w.
+// Il retourne le nombre d'écarts constatés.
+func runSelfTest(w io.Writer, cases []selfTestCase, indices []int) int {
+	oracles := make([]oracleValues, len(indices))
+	for i, n := range indices {
+		oracles[i] = oracle(n)
+	}
+
+	fmt.Fprintf(w, "Auto-test (%d indices, oracle par additions successives) :\n", len(indices))
+	failures := 0
+	for _, tc := range cases {
+		passed := 0
+		for i, n := range indices {
+			got, err := tc.Compute(n)
+			want := tc.Expected(oracles[i])
+			switch {
+			case err != nil:
+				fmt.Fprintf(w, "  ÉCHEC %s(%d) : %v\n", tc.Name, n, err)
+			case got.Cmp(want) != 0:
+				fmt.Fprintf(w, "  ÉCHEC %s(%d) : obtenu %s, attendu %s\n", tc.Name, n, truncateDigits(got.String(), 12), truncateDigits(want.String(), 12))
+			default:
+				passed++
+			}
+		}
+		status := "OK"
+		if passed < len(indices) {
+			status = "ÉCHEC"
+			failures += len(indices) - passed
+		}
+		fmt.Fprintf(w, "  %-22s: %d/%d %s\n", tc.Name, passed, len(indices), status)
+	}
+	return failures
+}
+
+// selfTestMode exécute -selftest : il écrit le résumé sur w et termine le
+// processus en erreur si un écart est constaté.
+func selfTestMode(w io.Writer, cases []selfTestCase, indices []int) {
+	if failures := runSelfTest(w, cases, indices); failures > 0 {
+		fatalf("Auto-test en échec : %d écart(s)", failures)
+	}
+}
@@ -0,0 +1,37 @@
+// =============================================================================
+// Génération de la suite F(0), F(1), …, F(n).
+//
+// Chaque terme est obtenu par une seule addition des deux précédents, ce qui
+// est bien moins coûteux que n calculs indépendants par doublement lorsque
+// toute la suite est demandée.
+// =============================================================================
+
+package main
+
+import (
+	"context"
+	"math/big"
+)
+
+// fibSequence émet F(from), F(from+1), …, F(to) sur le canal retourné, qui est
+// fermé à la fin de la suite ou dès l'annulation de ctx. Les valeurs émises
+// appartiennent au destinataire.
+func fibSequence(ctx context.Context, from, to int) <-chan *big.Int {
+	out := make(chan *big.Int)
+	go func() {
+		defer close(out)
+		a, b := big.NewInt(0), big.NewInt(1) // F(i), F(i+1)
+		for i := 0; i <= to; i++ {
+			if i >= from {
+				select {
+				case out <- new(big.Int).Set(a):
+				case <-ctx.Done():
+					return
+				}
+			}
+			a.Add(a, b)
+			a, b = b, a
+		}
+	}()
+	return out
+}
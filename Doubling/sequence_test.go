@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFibSequenceMatchesReference(t *testing.T) {
+	for _, r := range [][2]int{{0, 300}, {1, 10}, {250, 260}, {5, 5}} {
+		from, to := r[0], r[1]
+		i := from
+		for v := range fibSequence(context.Background(), from, to) {
+			if want, _ := fibDoublingPair(i); v.Cmp(want) != 0 {
+				t.Fatalf("fibSequence(%d, %d) : terme %d = %s ; attendu %s", from, to, i, v, want)
+			}
+			i++
+		}
+		if i != to+1 {
+			t.Errorf("fibSequence(%d, %d) : %d termes ; attendu %d", from, to, i-from, to-from+1)
+		}
+	}
+}
+
+func TestFibSequenceStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seq := fibSequence(ctx, 0, 1<<30)
+	for range 3 {
+		<-seq
+	}
+	cancel()
+	// Tant que le destinataire lit, select peut encore choisir l'envoi quelques
+	// fois avant de constater l'annulation ; le canal doit ensuite être fermé.
+	extra := 0
+	for range seq {
+		extra++
+		if extra > 64 {
+			t.Fatal("émission poursuivie après l'annulation")
+		}
+	}
+}
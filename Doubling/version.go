@@ -0,0 +1,61 @@
+// =============================================================================
+// Informations de version du binaire.
+//
+// Les variables version, commit et buildDate peuvent être fixées à la
+// compilation, par exemple :
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// À défaut, les informations enregistrées par la chaîne de compilation Go
+// (runtime/debug.ReadBuildInfo) sont utilisées.
+// =============================================================================
+
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Valeurs injectables via -ldflags "-X main.<nom>=<valeur>".
+var (
+	version   = ""
+	commit    = ""
+	buildDate = ""
+)
+
+// VersionInfo décrit le binaire en cours d'exécution.
+type VersionInfo struct {
+	Version   string `json:"version"`   // Version du module
+	Commit    string `json:"commit"`    // Révision git
+	BuildDate string `json:"buildDate"` // Date de compilation (ou du commit)
+	Go        string `json:"go"`        // Version de Go utilisée
+}
+
+// buildVersionInfo combine les valeurs injectées et les informations de compilation.
+func buildVersionInfo() VersionInfo {
+	info := VersionInfo{Version: version, Commit: commit, BuildDate: buildDate, Go: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	if info.Commit == "" {
+		info.Commit = "inconnu"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "inconnue"
+	}
+	return info
+}
@@ -0,0 +1,70 @@
+// =============================================================================
+// Représentation de Zeckendorf d'un entier.
+//
+// Tout entier strictement positif s'écrit de manière unique comme une somme de
+// nombres de Fibonacci d'indices non consécutifs (indices ≥ 2). L'algorithme
+// glouton retire à chaque étape le plus grand F(k) ≤ x ; le couple de départ
+// (F(k), F(k+1)) est obtenu par doublement, puis la suite est redescendue par
+// soustraction, F(k-1) = F(k+1) - F(k), sans autre multiplication.
+// =============================================================================
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Zeckendorf retourne, par ordre décroissant, les indices des nombres de
+// Fibonacci dont la somme vaut x. x doit être strictement positif.
+func Zeckendorf(x *big.Int) ([]uint64, error) {
+	if x.Sign() <= 0 {
+		return nil, fmt.Errorf("la représentation de Zeckendorf nécessite un entier strictement positif (reçu %s)", x)
+	}
+
+	// F(k) ≥ φ^(k-2) et log2(φ) > 2/3 : ce k garantit F(k) > x.
+	k := x.BitLen()*3/2 + 3
+	a, b := fibDoublingPair(k)
+	down := func() {
+		a, b = b.Sub(b, a), a
+		k--
+	}
+
+	rest := new(big.Int).Set(x)
+	var indices []uint64
+	for rest.Sign() > 0 {
+		for a.Cmp(rest) > 0 {
+			down()
+		}
+		rest.Sub(rest, a)
+		indices = append(indices, uint64(k))
+		// F(k-1) ne peut pas suivre F(k) : on saute directement à F(k-2).
+		down()
+		down()
+	}
+	return indices, nil
+}
+
+// formatZeckendorf retourne la représentation sous la forme « x = F(i) + F(j) + … ».
+func formatZeckendorf(x *big.Int, indices []uint64) string {
+	terms := make([]string, len(indices))
+	for i, k := range indices {
+		terms[i] = fmt.Sprintf("F(%d)", k)
+	}
+	return fmt.Sprintf("%s = %s", x, strings.Join(terms, " + "))
+}
+
+// parsePositiveInt interprète s comme un entier décimal, « _ » acceptés entre
+// deux chiffres.
+func parsePositiveInt(s string) (*big.Int, error) {
+	digits, err := stripDigitSeparators(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	x, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("entier invalide %q", s)
+	}
+	return x, nil
+}
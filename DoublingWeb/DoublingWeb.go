@@ -14,19 +14,80 @@
 // - numWorkers: nombre de workers parallèles (défaut: nombre de CPU)
 // - segmentSize: taille des segments de calcul (défaut: 1000)
 // - timeout: durée maximale en format Go (défaut: "5m")
+//
+// Métadonnées du résultat (chiffres, taille en bits, calcul parallèle), sans le transférer :
+// curl -X POST "http://localhost:8080/fibonacci?meta=1" -H "Content-Type: application/json" -d '{"m": 100}'
+//
+// Résultat complet en décimal, transmis en flux (transfert chunked) :
+// curl -X POST "http://localhost:8080/fibonacci?format=stream" -H "Content-Type: application/json" -d '{"m": 100000}'
+//
+// Même flux, suivi de l'empreinte SHA-256 des chiffres dans le trailer X-Result-SHA256 :
+// curl --raw -X POST "http://localhost:8080/fibonacci?format=stream&checksum=sha256" -H "Content-Type: application/json" -d '{"m": 100000}'
+//
+// Calcul par lot (les éléments sont calculés en parallèle, une erreur par élément
+// n'interrompt pas le lot) :
+// curl -X POST http://localhost:8080/fibonacci/batch -H "Content-Type: application/json" -d '{"ms": [10, 100, 1000], "timeout": "1m"}'
+//
+// Somme F(0) + … + F(n-1) par l'identité F(n+1) - 1, en O(log n) ; attention,
+// /fibonacci avec m somme F(0) + … + F(m-2), soit un terme de moins :
+// curl "http://localhost:8080/sum?n=1000000"
+//
+// Suite F(0), …, F(n) sous forme de tableau JSON (n ≤ 10000) :
+// curl "http://localhost:8080/sequence?n=20"
+//
+// Nombre de chiffres décimaux de F(n), sans calculer F(n) :
+// curl "http://localhost:8080/digits?n=1000000"
+//
+// Description OpenAPI 3 du service :
+// curl http://localhost:8080/openapi.json
+//
+// Sondes de vivacité et de disponibilité :
+// curl http://localhost:8080/livez
+// curl http://localhost:8080/readyz
+//
+// Version du serveur :
+// curl http://localhost:8080/version
+//
+// Options du serveur :
+// - -max-concurrent-calcs: nombre maximal de calculs simultanés (défaut: nombre de CPU).
+//   Au-delà, le serveur répond 503 avec un en-tête Retry-After.
+// - -concurrency-mode: reject (défaut) rejette immédiatement une requête excédentaire,
+//   wait la met en attente d'une place pendant au plus -queue-timeout (défaut: 10s).
+// - -max-batch-size: nombre maximal d'éléments par requête /fibonacci/batch (défaut: 100).
+// - -socket: chemin d'un socket Unix sur lequel écouter à la place du port TCP 8080.
+//   Exemple : curl --unix-socket /tmp/fib.sock http://localhost/version
+// - -result-cache-size: nombre de sommes conservées en mémoire (défaut: 128, 0 pour désactiver).
+//   L'en-tête X-Cache (HIT ou MISS) indique si le cache a servi ; ?nocache=1 le contourne.
+// - -seed-cache: valeurs de m, séparées par des virgules, dont la somme est calculée et mise en
+//   cache pendant le préchauffage, avant que /readyz ne réponde 200 (au plus
+//   -max-concurrent-calcs calculs simultanés).
+//   Exemple : -seed-cache "10,100,1000"
+// - -request-id-header: en-tête de l'identifiant de requête (défaut: X-Request-ID). Il est
+//   repris de la requête ou généré, renvoyé dans la réponse et ajouté aux journaux.
+// - -log-format: format des journaux, std (logger standard, défaut), text ou json (log/slog).
+// - -shutdown-timeout: à l'arrêt (SIGINT/SIGTERM), les nouveaux calculs reçoivent 503 et
+//   les calculs en cours disposent de ce délai pour se terminer (défaut: 30s).
+// - -version: affiche la version du serveur et s'arrête.
 
 package main
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -50,11 +111,42 @@ type APIRequest struct {
 
 // APIResponse représente la structure de la réponse JSON
 type APIResponse struct {
-	Result     string        `json:"result"`          // Résultat du calcul en notation scientifique
-	Duration   time.Duration `json:"duration"`        // Durée totale du calcul
-	Calculs    int64         `json:"calculations"`    // Nombre total de calculs effectués
-	TempsMoyen time.Duration `json:"averageTime"`     // Temps moyen par calcul
-	Error      string        `json:"error,omitempty"` // Message d'erreur (le cas échéant)
+	Result     string        `json:"result"`           // Résultat du calcul en notation scientifique
+	Duration   time.Duration `json:"duration"`         // Durée totale du calcul
+	Calculs    int64         `json:"calculations"`     // Nombre total de calculs effectués
+	TempsMoyen time.Duration `json:"averageTime"`      // Temps moyen par calcul
+	Error      string        `json:"error,omitempty"`  // Message d'erreur (le cas échéant)
+	Meta       *ResultMeta   `json:"meta,omitempty"`   // Métadonnées du résultat (avec ?meta=1)
+	Status     int           `json:"status,omitempty"` // Code HTTP équivalent d'un élément de lot en erreur (400 ou 500)
+}
+
+// ResultMeta décrit le résultat sans qu'il soit nécessaire de le transférer en entier.
+type ResultMeta struct {
+	Digits    int  `json:"digits"`    // Nombre de chiffres décimaux de la somme
+	BitLength int  `json:"bitLength"` // Taille en bits de la somme
+	Parallel  bool `json:"parallel"`  // Vrai si le calcul a été réparti sur plusieurs workers
+}
+
+// resultMeta construit les métadonnées de la somme des config.M premiers
+// nombres de Fibonacci. Le nombre de chiffres est exact pour les petites
+// sommes ; au-delà, il est déduit de l'identité somme = F(M) - 1, dont le
+// nombre de chiffres est celui de F(M) (jamais une puissance de 10 pour M ≥ 3).
+func resultMeta(config Configuration, sum *big.Int) *ResultMeta {
+	meta := &ResultMeta{
+		BitLength: sum.BitLen(),
+		Parallel:  config.NumWorkers > 1 && config.M-1 > config.SegmentSize,
+	}
+	if sum.BitLen() <= decimalLeafBits || config.M < 3 {
+		meta.Digits = len(sum.Text(10))
+	} else {
+		meta.Digits = int(DigitCount(uint64(config.M)))
+	}
+	return meta
+}
+
+// wantMeta indique si la requête demande les métadonnées du résultat.
+func wantMeta(r *http.Request) bool {
+	return r.URL.Query().Get("meta") == "1"
 }
 
 // DefaultConfig retourne une configuration par défaut avec des valeurs raisonnables.
@@ -150,6 +242,20 @@ func (fc *FibCalculator) Calculate(n int) (*big.Int, error) {
 	return new(big.Int).Set(fc.fk), nil // Retourner le résultat final
 }
 
+// PrefixSum calcule la somme des n premiers nombres de Fibonacci, F(0) + … + F(n-1),
+// grâce à l'identité Σ F(i) = F(n+1) - 1 : un seul calcul en O(log n) au lieu
+// d'une addition par terme.
+func (fc *FibCalculator) PrefixSum(n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, errors.New("n doit être non-négatif")
+	}
+	fn1, err := fc.Calculate(n + 1)
+	if err != nil {
+		return nil, err
+	}
+	return fn1.Sub(fn1, big.NewInt(1)), nil
+}
+
 // WorkerPool gère un pool de calculateurs réutilisables.
 type WorkerPool struct {
 	calculators []*FibCalculator // Liste des calculateurs disponibles
@@ -208,6 +314,18 @@ func computeSegment(ctx context.Context, start, end int, pool *WorkerPool, metri
 	return Result{Value: partialSum}                  // Retourner la somme partielle
 }
 
+// runSegment appelle computeSegment en convertissant une panique du worker en
+// erreur passagère : computeSumWithRetry relance alors le calcul, avec un
+// nouveau pool, au lieu que la panique fasse tomber le serveur.
+func runSegment(ctx context.Context, start, end int, pool *WorkerPool, metrics *Metrics) (result Result) {
+	defer func() {
+		if p := recover(); p != nil {
+			result = Result{Error: retryable(errors.Errorf("panique du worker sur le segment [%d, %d] : %v", start, end, p))}
+		}
+	}()
+	return computeSegment(ctx, start, end, pool, metrics)
+}
+
 // formatBigIntSci formate un grand nombre en notation scientifique.
 func formatBigIntSci(n *big.Int) string {
 	numStr := n.String()  // Convertir le nombre en chaîne de caractères
@@ -226,7 +344,9 @@ func formatBigIntSci(n *big.Int) string {
 	return fmt.Sprintf("%se%d", formattedNum, exponent) // Retourner le nombre en notation scientifique
 }
 
-// handleFibonacci gère les requêtes HTTP pour le calcul de Fibonacci
+// handleFibonacci gère les requêtes HTTP pour le calcul de Fibonacci. Par
+// convention historique, la somme porte sur F(0) + … + F(m-2) : m = 10 donne
+// 54, alors que /sum?n=10 donne F(0) + … + F(9) = 88.
 func handleFibonacci(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed) // Vérifier que la méthode est POST
@@ -239,6 +359,43 @@ func handleFibonacci(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	config, err := req.toConfig() // Fusionner la requête avec la configuration par défaut
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	checksum, err := wantChecksum(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Mode flux : les chiffres décimaux complets sont écrits au fil de l'eau
+	if r.URL.Query().Get("format") == "stream" {
+		streamSum(w, r, config, checksum)
+		return
+	}
+	if checksum {
+		http.Error(w, "checksum nécessite format=stream", http.StatusBadRequest)
+		return
+	}
+
+	response, hit := computeResponse(r.Context(), config, useCache(r), wantMeta(r)) // Effectuer le calcul
+
+	setCacheHeader(w, r, hit)
+	w.Header().Set("Content-Type", "application/json") // Définir le type de contenu de la réponse
+	if response.Error != "" {
+		w.WriteHeader(http.StatusInternalServerError) // Si une erreur est survenue, retourner un code d'erreur HTTP
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		loggerFrom(r.Context()).Error("erreur d'encodage de la réponse", "error", err) // Enregistrer toute erreur survenue lors de l'encodage de la réponse
+	}
+}
+
+// toConfig fusionne les valeurs fournies dans la requête avec la configuration par défaut.
+func (req APIRequest) toConfig() (Configuration, error) {
 	config := DefaultConfig() // Charger la configuration par défaut
 
 	// Mettre à jour la configuration avec les valeurs fournies par l'utilisateur
@@ -254,14 +411,60 @@ func handleFibonacci(w http.ResponseWriter, r *http.Request) {
 	if req.Timeout != "" {
 		timeout, err := time.ParseDuration(req.Timeout)
 		if err != nil {
-			http.Error(w, "Format de timeout invalide: "+err.Error(), http.StatusBadRequest) // Gérer les erreurs de format de timeout
-			return
+			return config, errors.Wrap(err, "Format de timeout invalide") // Gérer les erreurs de format de timeout
 		}
 		config.Timeout = timeout
 	}
+	if config.M < 0 {
+		return config, errors.Errorf("m doit être positif ou nul (reçu %d)", config.M)
+	}
+	if config.NumWorkers < 1 {
+		return config, errors.Errorf("numWorkers doit être supérieur ou égal à 1 (reçu %d)", config.NumWorkers)
+	}
+	if config.SegmentSize < 1 {
+		return config, errors.Errorf("segmentSize doit être supérieur ou égal à 1 (reçu %d)", config.SegmentSize)
+	}
+	return config, nil
+}
+
+// SumResult regroupe la somme calculée et les métriques de son calcul.
+type SumResult struct {
+	Sum          *big.Int      // Somme des termes (nil en cas d'erreur)
+	Duration     time.Duration // Durée totale du calcul
+	Calculations int64         // Nombre total de calculs effectués
+	AverageTime  time.Duration // Temps moyen par calcul
+}
+
+// computeResponse calcule la somme des config.M premiers nombres de Fibonacci et
+// construit la réponse API correspondante. En cas d'erreur, le champ Error est renseigné.
+// Si cached est vrai, le cache des sommes est consulté ; hit indique s'il a servi.
+// Si meta est vrai, les métadonnées du résultat sont jointes à la réponse.
+func computeResponse(parent context.Context, config Configuration, cached, meta bool) (response APIResponse, hit bool) {
+	res, hit, err := cachedSum(parent, config, cached)
+
+	// Construire la réponse API
+	response = APIResponse{
+		Duration:   res.Duration,
+		Calculs:    res.Calculations,
+		TempsMoyen: res.AverageTime,
+	}
+
+	if err != nil {
+		loggerFrom(parent).Error("échec du calcul", "m", config.M, "error", err)
+		response.Error = err.Error() // Enregistrer l'erreur si une erreur est survenue
+	} else {
+		response.Result = formatBigIntSci(res.Sum) // Formater le résultat final
+		if meta {
+			response.Meta = resultMeta(config, res.Sum)
+		}
+	}
+	return response, hit
+}
 
-	metrics := NewMetrics()                                         // Initialiser les métriques
-	ctx, cancel := context.WithTimeout(r.Context(), config.Timeout) // Créer un contexte avec délai d'attente
+// computeSum calcule en parallèle la somme des config.M premiers nombres de Fibonacci.
+func computeSum(parent context.Context, config Configuration) (SumResult, error) {
+	metrics := NewMetrics()                                    // Initialiser les métriques
+	ctx, cancel := context.WithTimeout(parent, config.Timeout) // Créer un contexte avec délai d'attente
 	defer cancel()
 
 	n := config.M - 1
@@ -278,9 +481,9 @@ func handleFibonacci(w http.ResponseWriter, r *http.Request) {
 
 		wg.Add(1)
 		go func(start, end int) {
-			defer wg.Done()                                          // Décrémenter le compteur quand la goroutine se termine
-			result := computeSegment(ctx, start, end, pool, metrics) // Calculer le segment
-			results <- result                                        // Envoyer le résultat au canal
+			defer wg.Done()                                      // Décrémenter le compteur quand la goroutine se termine
+			result := runSegment(ctx, start, end, pool, metrics) // Calculer le segment
+			results <- result                                    // Envoyer le résultat au canal
 		}(start, end)
 	}
 
@@ -293,46 +496,418 @@ func handleFibonacci(w http.ResponseWriter, r *http.Request) {
 	sumFib := new(big.Int) // Initialiser la somme totale des termes de Fibonacci
 	var calcError error
 
-	// Lire les résultats des goroutines
+	// Lire les résultats des goroutines. Après la première erreur, les segments
+	// restants sont annulés mais le canal est vidé pour ne bloquer aucune goroutine.
 	for result := range results {
 		if result.Error != nil {
-			calcError = result.Error
-			break
+			if calcError == nil {
+				calcError = result.Error
+				cancel()
+			}
+			continue
 		}
 		sumFib.Add(sumFib, result.Value) // Ajouter la valeur partielle à la somme totale
 	}
 
-	metrics.EndTime = time.Now()                                   // Enregistrer l'heure de fin
-	duration := metrics.EndTime.Sub(metrics.StartTime)             // Calculer la durée totale du calcul
-	avgTime := duration / time.Duration(metrics.TotalCalculations) // Calculer le temps moyen par calcul
-
-	// Construire la réponse API
-	response := APIResponse{
-		Duration:   duration,
-		Calculs:    metrics.TotalCalculations,
-		TempsMoyen: avgTime,
+	metrics.EndTime = time.Now()                       // Enregistrer l'heure de fin
+	duration := metrics.EndTime.Sub(metrics.StartTime) // Calculer la durée totale du calcul
+	var avgTime time.Duration
+	if metrics.TotalCalculations > 0 {
+		avgTime = duration / time.Duration(metrics.TotalCalculations) // Calculer le temps moyen par calcul
 	}
 
+	res := SumResult{
+		Duration:     duration,
+		Calculations: metrics.TotalCalculations,
+		AverageTime:  avgTime,
+	}
 	if calcError != nil {
-		response.Error = calcError.Error() // Enregistrer l'erreur si une erreur est survenue
-	} else {
-		response.Result = formatBigIntSci(sumFib) // Formater le résultat final
+		return res, calcError
 	}
+	res.Sum = sumFib
+	return res, nil
+}
 
-	w.Header().Set("Content-Type", "application/json") // Définir le type de contenu de la réponse
-	if calcError != nil {
-		w.WriteHeader(http.StatusInternalServerError) // Si une erreur est survenue, retourner un code d'erreur HTTP
+// BatchRequest représente une requête de calcul par lot : une valeur de m par
+// élément, les autres paramètres étant partagés par tous les éléments.
+type BatchRequest struct {
+	Ms          []int  `json:"ms"`                    // Valeurs de m à calculer
+	NumWorkers  *int   `json:"numWorkers,omitempty"`  // Nombre de workers parallèles (optionnel)
+	SegmentSize *int   `json:"segmentSize,omitempty"` // Taille des segments (optionnel)
+	Timeout     string `json:"timeout,omitempty"`     // Durée maximale par élément (optionnel)
+}
+
+// handleFibonacciBatch retourne le gestionnaire de /fibonacci/batch. Tous les
+// éléments sont validés avant le premier calcul : une option partagée invalide
+// fait échouer le lot (400), une valeur de m invalide seulement son élément
+// (status 400 dans sa réponse). Les éléments valides sont calculés en parallèle
+// dans la limite des places libres de sem (voir computeBatch) ; une erreur de
+// calcul est rapportée dans la réponse de l'élément (status 500) sans faire
+// échouer le lot.
+func handleFibonacciBatch(maxBatchSize int, sem chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var batch BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, "Erreur de décodage JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(batch.Ms) == 0 {
+			http.Error(w, "Le lot doit contenir au moins une valeur de m", http.StatusBadRequest)
+			return
+		}
+		if len(batch.Ms) > maxBatchSize {
+			http.Error(w, fmt.Sprintf("Lot trop grand: %d éléments (maximum %d)", len(batch.Ms), maxBatchSize), http.StatusBadRequest)
+			return
+		}
+
+		shared := APIRequest{NumWorkers: batch.NumWorkers, SegmentSize: batch.SegmentSize, Timeout: batch.Timeout}
+		if _, err := shared.toConfig(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		responses := make([]APIResponse, len(batch.Ms))
+		configs := make([]Configuration, len(batch.Ms))
+		var pending []int // Éléments valides, à calculer
+		for i, m := range batch.Ms {
+			req := shared
+			req.M = &m
+			config, err := req.toConfig()
+			if err != nil {
+				responses[i] = APIResponse{Error: err.Error(), Status: http.StatusBadRequest}
+				continue
+			}
+			configs[i] = config
+			pending = append(pending, i)
+		}
+
+		computeBatch(sem, pending, func(i int) {
+			responses[i], _ = computeResponse(r.Context(), configs[i], useCache(r), wantMeta(r)) // Chaque worker écrit dans sa propre case
+			if responses[i].Error != "" {
+				responses[i].Status = http.StatusInternalServerError
+			}
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(responses); err != nil {
+			loggerFrom(r.Context()).Error("erreur d'encodage de la réponse", "error", err)
+		}
+	}
+}
+
+// computeBatch appelle work pour chaque élément de items. L'appelant détient
+// déjà une place de sem, celle de la requête, qui sert au premier worker ; un
+// worker supplémentaire n'est lancé que pour chaque place obtenue sans attente.
+// Un lot ne dépasse donc jamais -max-concurrent-calcs et n'attend jamais une
+// place tenue par un autre lot.
+func computeBatch(sem chan struct{}, items []int, work func(int)) {
+	jobs := make(chan int, len(items))
+	for _, i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	worker := func() {
+		for i := range jobs {
+			work(i)
+		}
+	}
+
+	var wg sync.WaitGroup
+extra:
+	for n := 1; n < len(items); n++ {
+		select {
+		case sem <- struct{}{}:
+		default:
+			break extra // Plus de place libre : les workers lancés se partagent le reste
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			worker()
+		}()
+	}
+	worker()
+	wg.Wait()
+}
+
+// handleVersion retourne les informations de version du serveur au format JSON
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(buildVersionInfo()); err != nil {
+		loggerFrom(r.Context()).Error("erreur d'encodage de la réponse", "error", err)
 	}
+}
 
+// PrefixSumResponse est la réponse de l'endpoint /sum.
+type PrefixSumResponse struct {
+	N        uint64        `json:"n"`        // Nombre de termes sommés
+	Result   string        `json:"result"`   // F(0) + … + F(n-1) en notation scientifique
+	Duration time.Duration `json:"duration"` // Durée du calcul
+}
+
+// handlePrefixSum retourne la somme des n premiers nombres de Fibonacci,
+// F(0) + … + F(n-1), calculée par l'identité F(n+1) - 1 plutôt que terme à
+// terme. n compte les termes sommés, contrairement au m de /fibonacci qui en
+// somme m-1 : /sum?n=10 donne 88, /fibonacci avec m = 10 donne 54.
+func handlePrefixSum(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+	n, err := strconv.ParseUint(r.URL.Query().Get("n"), 10, 31)
+	if err != nil {
+		http.Error(w, "Paramètre n invalide : entier non négatif attendu", http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	sum, err := NewFibCalculator().PrefixSum(int(n))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := PrefixSumResponse{N: n, Result: formatBigIntSci(sum), Duration: time.Since(start)}
+
+	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Erreur d'encodage de la réponse: %v", err) // Enregistrer toute erreur survenue lors de l'encodage de la réponse
+		loggerFrom(r.Context()).Error("erreur d'encodage de la réponse", "error", err)
+	}
+}
+
+// openAPISpec est la description OpenAPI 3 du service, maintenue à la main dans
+// openapi.json ; elle doit être mise à jour avec chaque endpoint.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// handleOpenAPI retourne la description OpenAPI du service
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// DigitsResponse est la réponse de l'endpoint /digits.
+type DigitsResponse struct {
+	N      uint64 `json:"n"`      // Indice demandé
+	Digits uint64 `json:"digits"` // Nombre de chiffres décimaux de F(n)
+}
+
+// handleDigits retourne le nombre de chiffres décimaux de F(n) pour le paramètre n
+func handleDigits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+	n, err := strconv.ParseUint(r.URL.Query().Get("n"), 10, 64)
+	if err != nil {
+		http.Error(w, "Paramètre n invalide : entier non négatif attendu", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(DigitsResponse{N: n, Digits: DigitCount(n)}); err != nil {
+		loggerFrom(r.Context()).Error("erreur d'encodage de la réponse", "error", err)
+	}
+}
+
+// retryAfter est le délai suggéré au client lorsque le serveur est saturé.
+const retryAfter = 1 * time.Second
+
+// Comportements du limiteur lorsque toutes les places de calcul sont occupées.
+const (
+	ConcurrencyReject = "reject" // Rejet immédiat avec un code 503
+	ConcurrencyWait   = "wait"   // Attente d'une place, bornée par -queue-timeout
+)
+
+// limitConcurrency borne le nombre de calculs simultanés à la capacité du sémaphore.
+// Lorsque toutes les places sont occupées, la requête est rejetée avec un code
+// 503 et un en-tête Retry-After plutôt que de dégrader le serveur : immédiatement
+// en mode reject, ou après queueTimeout (ou l'annulation de la requête) en mode wait.
+func limitConcurrency(sem chan struct{}, mode string, queueTimeout time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !calcDrainer.Begin() {
+			loggerFrom(r.Context()).Warn("calcul rejeté, arrêt en cours", "method", r.Method, "path", r.URL.Path, "status", http.StatusServiceUnavailable)
+			w.Header().Set("Connection", "close")
+			http.Error(w, "Serveur en cours d'arrêt", http.StatusServiceUnavailable)
+			return
+		}
+		defer calcDrainer.Done()
+		if acquireSlot(r.Context(), sem, mode, queueTimeout) {
+			defer func() { <-sem }() // Libérer la place à la fin du calcul
+			next(w, r)
+			return
+		}
+		if r.Context().Err() != nil {
+			return // Le client est parti pendant l'attente : rien à répondre
+		}
+		loggerFrom(r.Context()).Warn("calcul rejeté, serveur saturé", "method", r.Method, "path", r.URL.Path, "mode", mode, "status", http.StatusServiceUnavailable)
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		http.Error(w, "Serveur saturé, réessayez plus tard", http.StatusServiceUnavailable)
+	}
+}
+
+// acquireSlot réserve une place dans sem. En mode wait, l'attente dure au plus
+// queueTimeout et cesse à l'annulation de ctx ; sinon seule une place libre
+// immédiatement est acceptée.
+func acquireSlot(ctx context.Context, sem chan struct{}, mode string, queueTimeout time.Duration) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+	if mode != ConcurrencyWait {
+		return false
+	}
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
 	}
 }
 
 func main() {
-	http.HandleFunc("/fibonacci", handleFibonacci) // Associer la route /fibonacci au gestionnaire
+	if err := run(); err != nil {
+		os.Exit(reportStartupError(os.Stderr, err))
+	}
+}
+
+// run configure puis exécute le serveur jusqu'à son arrêt. Une option invalide
+// est signalée par une erreur de configuration (voir startup.go).
+func run() error {
+	maxConcurrent := flag.Int("max-concurrent-calcs", runtime.NumCPU(), "Nombre maximal de calculs simultanés")
+	concurrencyMode := flag.String("concurrency-mode", ConcurrencyReject, "Comportement à saturation : reject (503 immédiat) ou wait (attente d'une place)")
+	queueTimeout := flag.Duration("queue-timeout", 10*time.Second, "Attente maximale d'une place de calcul en mode wait")
+	maxBatchSize := flag.Int("max-batch-size", 100, "Nombre maximal d'éléments par requête /fibonacci/batch")
+	socketPath := flag.String("socket", "", "Chemin d'un socket Unix sur lequel écouter (remplace le port TCP)")
+	logFormat := flag.String("log-format", LogFormatStd, "Format des journaux : std, text ou json")
+	cacheSize := flag.Int("result-cache-size", 128, "Nombre de sommes conservées en mémoire (0 pour désactiver le cache)")
+	requestIDHeader := flag.String("request-id-header", "X-Request-ID", "En-tête portant l'identifiant de requête, repris ou généré puis renvoyé")
+	seedList := flag.String("seed-cache", "", "Valeurs de m, séparées par des virgules, dont la somme est précalculée au démarrage")
+	flag.IntVar(&maxRetries, "max-retries", 2, "Nombre maximal de nouvelles tentatives d'un calcul ayant échoué sur une erreur passagère")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "Attente maximale de la fin des calculs en cours lors de l'arrêt")
+	showVersion := flag.Bool("version", false, "Affiche la version du serveur et s'arrête")
+	flag.Parse()
+	if *showVersion {
+		info := buildVersionInfo()
+		fmt.Printf("Version : %s\nCommit  : %s\nDate    : %s\nGo      : %s\n", info.Version, info.Commit, info.BuildDate, info.Go)
+		return nil
+	}
+	if *maxConcurrent < 1 {
+		return configErrorf("-max-concurrent-calcs doit être supérieur ou égal à 1 (reçu %d)", *maxConcurrent)
+	}
+	if *concurrencyMode != ConcurrencyReject && *concurrencyMode != ConcurrencyWait {
+		return configErrorf("-concurrency-mode doit valoir %s ou %s (reçu %q)", ConcurrencyReject, ConcurrencyWait, *concurrencyMode)
+	}
+	if *queueTimeout <= 0 {
+		return configErrorf("-queue-timeout doit être strictement positif (reçu %v)", *queueTimeout)
+	}
+	if maxRetries < 0 {
+		return configErrorf("-max-retries doit être positif ou nul (reçu %d)", maxRetries)
+	}
+	if *maxBatchSize < 1 {
+		return configErrorf("-max-batch-size doit être supérieur ou égal à 1 (reçu %d)", *maxBatchSize)
+	}
+	sumCache = NewSumCache(*cacheSize)
+	var err error
+	if logger, err = newLogger(*logFormat); err != nil {
+		return configErrorf("%v", err)
+	}
+	if *seedList != "" && sumCache == nil {
+		return configErrorf("-seed-cache nécessite -result-cache-size > 0")
+	}
+
+	sem := make(chan struct{}, *maxConcurrent) // Sémaphore borné des calculs en cours
+	limit := func(next http.HandlerFunc) http.HandlerFunc {
+		return limitConcurrency(sem, *concurrencyMode, *queueTimeout, next)
+	}
+	http.HandleFunc("/fibonacci", limit(handleFibonacci)) // Associer la route /fibonacci au gestionnaire
+	http.HandleFunc("/fibonacci/batch", limit(handleFibonacciBatch(*maxBatchSize, sem)))
+	http.HandleFunc("/sum", limit(handlePrefixSum))
+	http.HandleFunc("/sequence", limit(handleSequence))
+	http.HandleFunc("/digits", handleDigits)
+	http.HandleFunc("/version", handleVersion)
+	http.HandleFunc("/openapi.json", handleOpenAPI)
+	http.HandleFunc("/livez", handleLivez)
+	http.HandleFunc("/readyz", handleReadyz)
 
 	port := ":8080"
-	fmt.Printf("Serveur démarré sur le port %s\n", port) // Afficher un message pour indiquer que le serveur est démarré
-	log.Fatal(http.ListenAndServe(port, nil))            // Lancer le serveur HTTP sur le port 8080
+	listener, err := listen(port, *socketPath)
+	if err != nil {
+		return errors.Wrap(err, "impossible de démarrer le serveur")
+	}
+	if *socketPath != "" {
+		fmt.Printf("Serveur démarré sur le socket %s\n", *socketPath)
+	} else {
+		fmt.Printf("Serveur démarré sur le port %s\n", port) // Afficher un message pour indiquer que le serveur est démarré
+	}
+
+	// Arrêt propre sur SIGINT/SIGTERM : le socket Unix est supprimé à la fermeture du listener
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Handler: requestIDMiddleware(*requestIDHeader, loggingMiddleware(http.DefaultServeMux))}
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		ready.Store(false) // Ne plus recevoir de trafic pendant l'arrêt
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		log.Printf("Arrêt demandé, attente des calculs en cours (au plus %v)", *shutdownTimeout)
+		if err := calcDrainer.Drain(shutdownCtx); err != nil {
+			log.Printf("Calculs toujours en cours à l'expiration du délai d'arrêt: %v", err)
+		}
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Erreur lors de l'arrêt du serveur: %v", err)
+		}
+	}()
+	go func() {
+		// /readyz reste à 503 tant que le préchauffage n'a pas abouti
+		if err := warmUp(ctx, *seedList, *maxConcurrent); err != nil {
+			logger.Error("préchauffage en échec, le serveur reste indisponible", "error", err)
+		}
+	}()
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	<-shutdownDone // Serve rend la main dès le début de Shutdown : attendre sa fin
+	return nil
+}
+
+// listen ouvre le listener du serveur : un socket Unix si socketPath est fourni,
+// sinon le port TCP addr. Un fichier de socket résiduel (laissé par une exécution
+// précédente interrompue) est supprimé avant l'écoute.
+func listen(addr, socketPath string) (net.Listener, error) {
+	if socketPath == "" {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Stat(socketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, errors.Errorf("%s existe et n'est pas un socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, errors.Wrap(err, "suppression du socket résiduel")
+		}
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "écoute sur %s", socketPath)
+	}
+	return listener, nil
 }
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// postBatch envoie body à /fibonacci/batch et retourne la réponse enregistrée.
+func postBatch(t *testing.T, body string, sem chan struct{}) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/fibonacci/batch", strings.NewReader(body))
+	handleFibonacciBatch(10, sem)(rec, req)
+	return rec
+}
+
+func TestFibonacciBatchPartialFailures(t *testing.T) {
+	rec := postBatch(t, `{"ms": [10, -1, 20]}`, make(chan struct{}, 1))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code %d, attendu 200 : %s", rec.Code, rec.Body)
+	}
+	var responses []APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("%d réponses, attendu 3", len(responses))
+	}
+	tests := []struct {
+		result string
+		status int
+	}{
+		{formatBigIntSci(big.NewInt(54)), 0},   // F(0) + … + F(8)
+		{"", http.StatusBadRequest},            // m négatif
+		{formatBigIntSci(big.NewInt(6764)), 0}, // F(0) + … + F(18) = F(20) - 1
+	}
+	for i, tt := range tests {
+		got := responses[i]
+		if got.Result != tt.result || got.Status != tt.status {
+			t.Errorf("élément %d : result %q status %d (erreur %q), attendu %q status %d", i, got.Result, got.Status, got.Error, tt.result, tt.status)
+		}
+	}
+}
+
+func TestFibonacciBatchRejectsInvalidSharedOptions(t *testing.T) {
+	for _, body := range []string{
+		`{"ms": [10], "timeout": "bientôt"}`,
+		`{"ms": [10], "numWorkers": 0}`,
+		`{"ms": [10], "segmentSize": -5}`,
+		`{"ms": []}`,
+		`{"ms": [1,2,3,4,5,6,7,8,9,10,11]}`,
+	} {
+		if rec := postBatch(t, body, make(chan struct{}, 1)); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s : code %d, attendu 400", body, rec.Code)
+		}
+	}
+}
+
+func TestComputeBatchRespectsSemaphore(t *testing.T) {
+	for _, capacity := range []int{1, 2, 4} {
+		sem := make(chan struct{}, capacity)
+		sem <- struct{}{} // Place détenue par la requête du lot
+
+		var running, peak atomic.Int32
+		var mu sync.Mutex
+		done := map[int]bool{}
+		items := []int{0, 1, 2, 3, 4, 5, 6, 7}
+		computeBatch(sem, items, func(i int) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			mu.Lock()
+			done[i] = true
+			mu.Unlock()
+		})
+
+		if len(done) != len(items) {
+			t.Errorf("capacité %d : %d éléments traités, attendu %d", capacity, len(done), len(items))
+		}
+		if int(peak.Load()) > capacity {
+			t.Errorf("capacité %d : %d calculs simultanés", capacity, peak.Load())
+		}
+		if len(sem) != 1 {
+			t.Errorf("capacité %d : %d places occupées après le lot, attendu 1", capacity, len(sem))
+		}
+	}
+}
+
+func TestPrefixSumMatchesBruteForce(t *testing.T) {
+	fc := NewFibCalculator()
+	want, a, b := new(big.Int), big.NewInt(0), big.NewInt(1) // Σ F(i) pour i < n ; F(n), F(n+1)
+	for n := 0; n <= 300; n++ {
+		got, err := fc.PrefixSum(n)
+		if err != nil {
+			t.Fatalf("PrefixSum(%d) : %v", n, err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("PrefixSum(%d) = %s, attendu %s", n, got, want)
+		}
+		want.Add(want, a)
+		a.Add(a, b)
+		a, b = b, a
+	}
+}
+
+// TestSumAndFibonacciConventions fige la différence documentée entre /sum, qui
+// somme n termes, et /fibonacci, qui en somme m - 1.
+func TestSumAndFibonacciConventions(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handlePrefixSum(rec, httptest.NewRequest(http.MethodGet, "/sum?n=10", nil))
+	var sum PrefixSumResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &sum); err != nil {
+		t.Fatalf("/sum : %v (%s)", err, rec.Body)
+	}
+	if want := formatBigIntSci(big.NewInt(88)); sum.Result != want {
+		t.Errorf("/sum?n=10 = %s, attendu %s (F(11) - 1)", sum.Result, want)
+	}
+
+	rec = httptest.NewRecorder()
+	handleFibonacci(rec, httptest.NewRequest(http.MethodPost, "/fibonacci", strings.NewReader(`{"m": 10}`)))
+	var fib APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &fib); err != nil {
+		t.Fatalf("/fibonacci : %v (%s)", err, rec.Body)
+	}
+	if want := formatBigIntSci(big.NewInt(54)); fib.Result != want {
+		t.Errorf("/fibonacci m=10 = %s, attendu %s (F(0) + … + F(8))", fib.Result, want)
+	}
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		busy       bool          // Sémaphore plein à l'arrivée de la requête
+		releaseIn  time.Duration // Libération de la place occupée pendant l'attente (0 : jamais)
+		wantStatus int
+	}{
+		{"place libre", ConcurrencyReject, false, 0, http.StatusOK},
+		{"saturé, mode reject", ConcurrencyReject, true, 0, http.StatusServiceUnavailable},
+		{"saturé, mode wait, délai expiré", ConcurrencyWait, true, 0, http.StatusServiceUnavailable},
+		{"saturé, mode wait, place libérée", ConcurrencyWait, true, 10 * time.Millisecond, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sem := make(chan struct{}, 1)
+			if tt.busy {
+				sem <- struct{}{}
+			}
+			if tt.releaseIn > 0 {
+				time.AfterFunc(tt.releaseIn, func() { <-sem })
+			}
+			called := false
+			handler := limitConcurrency(sem, tt.mode, 50*time.Millisecond, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if len(sem) != 1 {
+					t.Error("le calcul s'exécute sans occuper de place")
+				}
+			})
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/fibonacci", nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("code = %d, attendu %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusServiceUnavailable {
+				if called {
+					t.Error("calcul exécuté malgré le rejet")
+				}
+				if got := rec.Header().Get("Retry-After"); got != "1" {
+					t.Errorf("Retry-After = %q, attendu \"1\"", got)
+				}
+				return
+			}
+			if !called {
+				t.Error("calcul non exécuté")
+			}
+			if len(sem) != 0 {
+				t.Error("place non libérée à la fin du calcul")
+			}
+		})
+	}
+}
+
+// isDraining indique si l'arrêt de d a commencé.
+func isDraining(d *Drainer) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+func TestDrainCompletesInFlightAndRejectsNew(t *testing.T) {
+	t.Cleanup(func() { calcDrainer = Drainer{} })
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	handler := limitConcurrency(make(chan struct{}, 4), ConcurrencyReject, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release // Calcul long simulé, terminé à la demande du test
+		}
+		io.WriteString(w, "terminé")
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	type reply struct {
+		status int
+		body   string
+		err    error
+	}
+	inFlight := make(chan reply, 1)
+	go func() {
+		resp, err := http.Post(srv.URL, "application/json", nil)
+		if err != nil {
+			inFlight <- reply{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		inFlight <- reply{resp.StatusCode, string(body), err}
+	}()
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- calcDrainer.Drain(context.Background()) }()
+	for !isDraining(&calcDrainer) {
+		time.Sleep(time.Millisecond)
+	}
+
+	resp, err := http.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("nouvelle requête pendant l'arrêt : code %d, attendu 503", resp.StatusCode)
+	}
+	if !resp.Close { // Le client traduit « Connection: close » en resp.Close
+		t.Error("réponse sans Connection: close")
+	}
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain terminé (%v) avant la fin du calcul en cours", err)
+	default:
+	}
+
+	close(release)
+	if r := <-inFlight; r.err != nil || r.status != http.StatusOK || r.body != "terminé" {
+		t.Errorf("calcul en cours : %d %q (%v), attendu 200 « terminé »", r.status, r.body, r.err)
+	}
+	if err := <-drained; err != nil {
+		t.Errorf("Drain : %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("%d calculs exécutés, attendu 1", calls.Load())
+	}
+}
+
+func TestDrainStopsWaitingAtDeadline(t *testing.T) {
+	var d Drainer
+	if !d.Begin() {
+		t.Fatal("Begin refusé avant l'arrêt")
+	}
+	defer d.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain = %v, attendu context.DeadlineExceeded", err)
+	}
+	if d.Begin() {
+		t.Error("Begin accepté après le début de l'arrêt")
+	}
+}
+
+// unixClient retourne un client HTTP dont toutes les connexions passent par
+// le socket Unix socketPath.
+func unixClient(socketPath string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+}
+
+func TestListenUnixSocketServesLivez(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "fib.sock")
+	// Un socket résiduel d'une exécution précédente est remplacé.
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := listen(":0", socketPath)
+	if err != nil {
+		t.Fatalf("listen : %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(handleLivez)}
+	go server.Serve(listener)
+	defer server.Close()
+
+	resp, err := unixClient(socketPath).Get("http://unix/livez")
+	if err != nil {
+		t.Fatalf("GET /livez via %s : %v", socketPath, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok\n" {
+		t.Errorf("réponse %d %q ; attendu 200 \"ok\\n\"", resp.StatusCode, body)
+	}
+
+	server.Close()
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("socket toujours présent après la fermeture : %v", err)
+	}
+}
+
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleOpenAPI(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("code %d, Content-Type %q ; attendu 200 application/json", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("description OpenAPI invalide : %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi = %q ; attendu une version 3.x", spec.OpenAPI)
+	}
+	// Routes enregistrées par run.
+	for _, path := range []string{"/fibonacci", "/fibonacci/batch", "/sum", "/sequence", "/digits", "/version", "/openapi.json", "/livez", "/readyz"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("chemin %s absent de paths", path)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handleOpenAPI(rec, httptest.NewRequest(http.MethodPost, "/openapi.json", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST : code %d ; attendu 405", rec.Code)
+	}
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleVersion(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("code %d, Content-Type %q ; attendu 200 application/json", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("réponse invalide %q : %v", rec.Body, err)
+	}
+	for _, key := range []string{"version", "commit", "buildDate", "go"} {
+		if fields[key] == "" {
+			t.Errorf("clé %q absente ou vide : %v", key, fields)
+		}
+	}
+	if len(fields) != 4 {
+		t.Errorf("%d clés, attendu 4 : %v", len(fields), fields)
+	}
+}
+
+func TestFibonacciMetaOnlyWhenRequested(t *testing.T) {
+	withSumCache(t, 0)
+	body := `{"m": 100, "numWorkers": 4, "segmentSize": 10}`
+	var plain map[string]json.RawMessage
+	if err := json.Unmarshal(postFibonacci("", body).Body.Bytes(), &plain); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := plain["meta"]; ok {
+		t.Errorf("meta présent sans ?meta=1 : %s", plain["meta"])
+	}
+
+	var resp APIResponse
+	if err := json.Unmarshal(postFibonacci("?meta=1", body).Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// m = 100 : F(0) + … + F(98) = F(100) - 1 = 354224848179261915074.
+	want := ResultMeta{Digits: 21, BitLength: 69, Parallel: true}
+	if resp.Meta == nil || *resp.Meta != want {
+		t.Fatalf("meta = %+v ; attendu %+v", resp.Meta, want)
+	}
+
+	if err := json.Unmarshal(postFibonacci("?meta=1", `{"m": 100, "numWorkers": 1}`).Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Meta == nil || resp.Meta.Parallel {
+		t.Errorf("un seul worker : meta = %+v ; attendu parallel faux", resp.Meta)
+	}
+}
+
+func TestResultMetaDigitEstimate(t *testing.T) {
+	// Au-delà de decimalLeafBits, le nombre de chiffres est déduit de F(m).
+	for _, m := range []int{3, 1000, 50_000, 50_001} {
+		a, b := big.NewInt(0), big.NewInt(1) // F(i), F(i+1)
+		for range m {
+			a.Add(a, b)
+			a, b = b, a
+		}
+		sum := a.Sub(a, big.NewInt(1)) // F(m) - 1
+		meta := resultMeta(Configuration{M: m, NumWorkers: 1}, sum)
+		if want := len(sum.Text(10)); meta.Digits != want || meta.BitLength != sum.BitLen() {
+			t.Errorf("m = %d : %+v ; attendu %d chiffres et %d bits", m, meta, want, sum.BitLen())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Cache en mémoire des sommes déjà calculées.
+//
+// La somme ne dépend que de m : numWorkers, segmentSize et timeout modifient la
+// façon de calculer, pas le résultat. Les entrées sont donc indexées par m et
+// évincées selon la politique LRU (la moins récemment utilisée en premier).
+// Une requête portant ?nocache=1 contourne le cache, en lecture comme en écriture.
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SumCache est un cache LRU borné des sommes, sûr en accès concurrent.
+// Un cache nil est valide et ne conserve rien.
+type SumCache struct {
+	capacity int
+	order    *list.List            // Entrées de la plus récente à la plus ancienne
+	entries  map[int]*list.Element // Accès direct par m
+	mutex    sync.Mutex
+}
+
+// sumCacheEntry est la valeur stockée dans la liste LRU.
+type sumCacheEntry struct {
+	m   int
+	sum *big.Int
+}
+
+// sumCache est le cache partagé par les gestionnaires (nil si désactivé).
+var sumCache *SumCache
+
+// NewSumCache crée un cache de capacity entrées, ou nil si capacity vaut 0.
+func NewSumCache(capacity int) *SumCache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &SumCache{capacity: capacity, order: list.New(), entries: make(map[int]*list.Element)}
+}
+
+// Get retourne la somme associée à m et la marque comme récemment utilisée.
+// La valeur retournée est partagée et ne doit pas être modifiée.
+func (c *SumCache) Get(m int) (*big.Int, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[m]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*sumCacheEntry).sum, true
+}
+
+// Put enregistre la somme associée à m, en évinçant l'entrée la plus ancienne
+// si le cache est plein.
+func (c *SumCache) Put(m int, sum *big.Int) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, ok := c.entries[m]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[m] = c.order.PushFront(&sumCacheEntry{m: m, sum: sum})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sumCacheEntry).m)
+	}
+}
+
+// useCache indique si la requête autorise l'utilisation du cache.
+func useCache(r *http.Request) bool {
+	return r.URL.Query().Get("nocache") != "1"
+}
+
+// setCacheHeader renseigne l'en-tête X-Cache (HIT ou MISS) lorsque le cache est actif.
+func setCacheHeader(w http.ResponseWriter, r *http.Request, hit bool) {
+	if sumCache == nil || !useCache(r) {
+		return
+	}
+	if hit {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+}
+
+// cachedSum retourne la somme depuis le cache si cached est vrai et qu'elle y
+// figure ; sinon elle est calculée par computeSum, avec les nouvelles tentatives
+// de computeSumWithRetry, puis enregistrée.
+func cachedSum(parent context.Context, config Configuration, cached bool) (res SumResult, hit bool, err error) {
+	if !cached {
+		res, err = computeSumWithRetry(parent, config, maxRetries, computeSum)
+		return res, false, err
+	}
+	start := time.Now()
+	if sum, ok := sumCache.Get(config.M); ok {
+		return SumResult{Sum: sum, Duration: time.Since(start)}, true, nil
+	}
+	res, err = computeSumWithRetry(parent, config, maxRetries, computeSum)
+	if err == nil {
+		sumCache.Put(config.M, res.Sum)
+	}
+	return res, false, err
+}
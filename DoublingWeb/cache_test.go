@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// withSumCache installe un cache de capacity entrées pour la durée du test.
+func withSumCache(t *testing.T, capacity int) {
+	t.Helper()
+	saved := sumCache
+	sumCache = NewSumCache(capacity)
+	t.Cleanup(func() { sumCache = saved })
+}
+
+// postFibonacci envoie body à /fibonacci avec la requête query.
+func postFibonacci(query, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handleFibonacci(rec, httptest.NewRequest(http.MethodPost, "/fibonacci"+query, strings.NewReader(body)))
+	return rec
+}
+
+func TestSumCacheServesRepeatedRequest(t *testing.T) {
+	withSumCache(t, 8)
+
+	// Le flux ne contient que les chiffres : les deux corps doivent être identiques.
+	first := postFibonacci("?format=stream", `{"m": 300}`)
+	second := postFibonacci("?format=stream", `{"m": 300}`)
+	if got := first.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("première requête : X-Cache = %q, attendu MISS", got)
+	}
+	if got := second.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("seconde requête : X-Cache = %q, attendu HIT", got)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Error("corps servi par le cache différent du corps calculé")
+	}
+
+	// En JSON, seules les mesures de durée diffèrent ; le résultat est identique.
+	var calc, hit APIResponse
+	json.Unmarshal(postFibonacci("", `{"m": 300}`).Body.Bytes(), &calc)
+	rec := postFibonacci("?meta=1", `{"m": 300}`)
+	json.Unmarshal(rec.Body.Bytes(), &hit)
+	if rec.Header().Get("X-Cache") != "HIT" || hit.Result != calc.Result || hit.Result == "" {
+		t.Errorf("JSON : X-Cache = %q, résultat %q, attendu HIT et %q", rec.Header().Get("X-Cache"), hit.Result, calc.Result)
+	}
+
+	if rec := postFibonacci("?format=stream&nocache=1", `{"m": 300}`); rec.Header().Get("X-Cache") != "" {
+		t.Errorf("nocache=1 : X-Cache = %q, attendu absent", rec.Header().Get("X-Cache"))
+	}
+}
+
+func TestSumCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewSumCache(2)
+	c.Put(1, big.NewInt(10))
+	c.Put(2, big.NewInt(20))
+	c.Get(1)                 // 2 devient la moins récemment utilisée
+	c.Put(3, big.NewInt(30)) // Évince 2
+	for m, want := range map[int]bool{1: true, 2: false, 3: true} {
+		if _, ok := c.Get(m); ok != want {
+			t.Errorf("Get(%d) présent = %v, attendu %v", m, ok, want)
+		}
+	}
+
+	c.Put(3, big.NewInt(99)) // Déjà présente : ni doublon ni remplacement
+	if v, _ := c.Get(3); v.Int64() != 30 || c.order.Len() != 2 || len(c.entries) != 2 {
+		t.Errorf("après un second Put(3) : valeur %v, %d entrées", v, c.order.Len())
+	}
+	c.Put(4, big.NewInt(40)) // Évince 1, désormais la plus ancienne
+	if _, ok := c.Get(1); ok {
+		t.Error("Get(1) : entrée non évincée")
+	}
+}
+
+func TestNilSumCache(t *testing.T) {
+	if NewSumCache(0) != nil {
+		t.Fatal("NewSumCache(0) doit retourner nil")
+	}
+	var c *SumCache
+	c.Put(1, big.NewInt(1))
+	if _, ok := c.Get(1); ok {
+		t.Error("Get sur un cache nil : succès inattendu")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// TestDigitCountMatchesSequence compare DigitCount au nombre de chiffres de
+// chaque terme F(0) … F(50 000), obtenu par additions successives. Le nombre de
+// chiffres est suivi en comparant F(n) à la plus petite puissance de 10 qui le
+// dépasse, ce qui évite une conversion décimale par terme. L'intervalle est
+// découpé en tranches vérifiées en parallèle.
+func TestDigitCountMatchesSequence(t *testing.T) {
+	const limit, chunk = 50_000, 5_000
+	for start := 0; start <= limit; start += chunk {
+		t.Run(fmt.Sprintf("%d", start), func(t *testing.T) {
+			t.Parallel()
+			fc := NewFibCalculator()
+			a, _ := fc.Calculate(start) // F(start), F(start+1)
+			b, _ := fc.Calculate(start + 1)
+			digits := uint64(len(a.String()))
+			next := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+			for n := start; n < start+chunk && n <= limit; n++ {
+				for a.Cmp(next) >= 0 {
+					digits++
+					next.Mul(next, big.NewInt(10))
+				}
+				if got := DigitCount(uint64(n)); got != digits {
+					t.Fatalf("DigitCount(%d) = %d, attendu %d", n, got, digits)
+				}
+				a.Add(a, b)
+				a, b = b, a
+			}
+		})
+	}
+}
+
+func TestDigitCountMatchesDecimalString(t *testing.T) {
+	for _, n := range []uint64{0, 1, 2, 3, 7, 45, 100, 1000, 4782, 10_000, 50_000, 250_000, 1_000_000} {
+		v, err := NewFibCalculator().Calculate(int(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := DigitCount(n), uint64(len(v.String())); got != want {
+			t.Errorf("DigitCount(%d) = %d, attendu %d", n, got, want)
+		}
+	}
+}
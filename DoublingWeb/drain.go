@@ -0,0 +1,62 @@
+// Arrêt progressif (drain) des calculs en cours.
+//
+// À la réception d'un signal d'arrêt, le serveur cesse d'accepter de nouveaux
+// calculs (réponse 503) mais laisse ceux déjà commencés se terminer, pendant au
+// plus -shutdown-timeout, avant de fermer le serveur HTTP. Les requêtes légères
+// (/digits, /version, sondes…) restent servies jusqu'à la fermeture.
+
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// Drainer compte les calculs en cours et refuse les nouveaux une fois l'arrêt
+// commencé. Le mutex garantit qu'aucun calcul n'est enregistré après le début
+// de l'attente, ce que sync.WaitGroup seul ne permet pas.
+type Drainer struct {
+	mu       sync.Mutex
+	draining bool
+	active   sync.WaitGroup
+}
+
+// calcDrainer suit les calculs lancés par les routes limitées par limitConcurrency.
+var calcDrainer Drainer
+
+// Begin enregistre un nouveau calcul. Il retourne false si l'arrêt a commencé ;
+// sinon l'appelant doit appeler Done à la fin du calcul.
+func (d *Drainer) Begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.active.Add(1)
+	return true
+}
+
+// Done signale la fin d'un calcul enregistré par Begin.
+func (d *Drainer) Done() {
+	d.active.Done()
+}
+
+// Drain refuse les nouveaux calculs puis attend la fin des calculs en cours,
+// au plus jusqu'à l'annulation de ctx, dont l'erreur est alors retournée.
+func (d *Drainer) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,66 @@
+// Sondes de vivacité et de disponibilité (par exemple pour Kubernetes).
+//
+// /livez répond 200 tant que le processus sert des requêtes. /readyz répond 200
+// uniquement lorsque le serveur accepte du travail : après le préchauffage
+// (préchargement éventuel du cache, puis un calcul de contrôle dont le résultat
+// est vérifié) et avant le début de l'arrêt ; sinon il répond 503 afin que le
+// répartiteur de charge cesse d'y envoyer des requêtes. Un préchauffage en
+// échec laisse /readyz à 503.
+
+package main
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Calcul de contrôle du préchauffage : F(0) + … + F(8), soit 54.
+const (
+	warmUpM   = 10
+	warmUpSum = 54
+)
+
+// ready indique si le serveur accepte de nouvelles requêtes de calcul.
+var ready atomic.Bool
+
+// warmUp précharge le cache avec seedList (si elle n'est pas vide), puis
+// vérifie le résultat d'un calcul de contrôle. ready n'est positionné qu'en
+// cas de succès.
+func warmUp(ctx context.Context, seedList string, workers int) error {
+	if seedList != "" {
+		seedCache(ctx, seedList, workers)
+	}
+	config := DefaultConfig()
+	config.M = warmUpM
+	res, err := computeSum(ctx, config)
+	if err != nil {
+		return errors.Wrap(err, "calcul de contrôle en échec")
+	}
+	if res.Sum.Cmp(big.NewInt(warmUpSum)) != 0 {
+		return errors.Errorf("calcul de contrôle incorrect : m=%d donne %s au lieu de %d", warmUpM, res.Sum, warmUpSum)
+	}
+	ready.Store(true)
+	return nil
+}
+
+// handleLivez signale que le processus est vivant.
+func handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "ok\n")
+}
+
+// handleReadyz signale si le serveur est prêt à recevoir du trafic.
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "indisponible\n")
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
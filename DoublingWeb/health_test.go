@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// readyzStatus retourne le code de réponse de /readyz.
+func readyzStatus() int {
+	rec := httptest.NewRecorder()
+	handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return rec.Code
+}
+
+func TestReadyzFollowsWarmUp(t *testing.T) {
+	ready.Store(false)
+	t.Cleanup(func() { ready.Store(false) })
+	if got := readyzStatus(); got != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz avant le préchauffage = %d, attendu 503", got)
+	}
+	if err := warmUp(context.Background(), "", 1); err != nil {
+		t.Fatalf("warmUp : %v", err)
+	}
+	if got := readyzStatus(); got != http.StatusOK {
+		t.Fatalf("/readyz après le préchauffage = %d, attendu 200", got)
+	}
+}
+
+func TestWarmUpFailureKeepsReadyzUnavailable(t *testing.T) {
+	ready.Store(false)
+	t.Cleanup(func() { ready.Store(false) })
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := warmUp(ctx, "", 1); err == nil {
+		t.Fatal("warmUp avec un contexte annulé : erreur attendue")
+	}
+	if got := readyzStatus(); got != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz après un préchauffage en échec = %d, attendu 503", got)
+	}
+}
+
+func TestWarmUpSeedsCache(t *testing.T) {
+	saved := sumCache
+	sumCache = NewSumCache(8)
+	t.Cleanup(func() { sumCache = saved; ready.Store(false) })
+	if err := warmUp(context.Background(), "20, 30", 2); err != nil {
+		t.Fatalf("warmUp : %v", err)
+	}
+	for _, m := range []int{20, 30} {
+		config := DefaultConfig()
+		config.M = m
+		if _, hit, err := cachedSum(context.Background(), config, true); err != nil || !hit {
+			t.Errorf("m=%d : hit=%v, err=%v ; attendu servi par le cache", m, hit, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Journalisation structurée du serveur avec log/slog.
+//
+// Chaque requête reçoit un identifiant, repris de l'en-tête X-Request-ID (nom
+// configurable par -request-id-header) ou généré, renvoyé dans la réponse et
+// ajouté à toutes les lignes de journal émises pour cette requête.
+//
+// Par défaut (-log-format std), les journaux passent par le logger standard du
+// package log, comme auparavant. Les formats text et json produisent des
+// enregistrements clé/valeur (method, path, status, duration_ms, m, …)
+// exploitables par un outil d'agrégation.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Formats de journalisation acceptés par -log-format.
+const (
+	LogFormatStd  = "std"  // Logger standard du package log (défaut)
+	LogFormatText = "text" // slog.TextHandler sur la sortie d'erreur
+	LogFormatJSON = "json" // slog.JSONHandler sur la sortie d'erreur
+)
+
+// logger est le journal structuré utilisé par les gestionnaires HTTP.
+var logger = slog.Default()
+
+// newLogger construit le journal correspondant au format demandé.
+func newLogger(format string) (*slog.Logger, error) {
+	switch format {
+	case LogFormatStd, "":
+		return slog.Default(), nil
+	case LogFormatText:
+		return slog.New(slog.NewTextHandler(os.Stderr, nil)), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil)), nil
+	default:
+		return nil, errors.Errorf("format de journalisation inconnu %q (std, text ou json)", format)
+	}
+}
+
+// statusRecorder mémorise le code de statut écrit par le gestionnaire.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader enregistre le code de statut avant de le transmettre.
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Flush préserve le transfert en flux (?format=stream) à travers l'enveloppe.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// requestIDKey est la clé de contexte de l'identifiant de requête.
+type requestIDKey struct{}
+
+// maxRequestIDLength borne la taille d'un identifiant fourni par le client.
+const maxRequestIDLength = 128
+
+// newRequestID génère un identifiant aléatoire de 16 caractères hexadécimaux.
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID accepte un identifiant client court composé de caractères
+// ASCII imprimables, afin qu'il ne puisse pas corrompre les journaux.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDMiddleware associe un identifiant à chaque requête : celui de
+// l'en-tête header s'il est valide, sinon un identifiant généré. Il est placé
+// dans le contexte de la requête et renvoyé dans le même en-tête de réponse.
+func requestIDMiddleware(header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(header)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(header, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// loggerFrom retourne le journal enrichi de l'identifiant de requête de ctx,
+// ou le journal global si ctx n'en porte pas.
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return logger.With("request_id", id)
+	}
+	return logger
+}
+
+// loggingMiddleware journalise chaque requête une fois traitée.
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		loggerFrom(r.Context()).Info("requête traitée",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	})
+}
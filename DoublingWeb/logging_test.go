@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureHandler est un slog.Handler qui conserve les enregistrements émis.
+type captureHandler struct {
+	mu      *sync.Mutex
+	records *[]slog.Record
+	attrs   []slog.Attr
+}
+
+func newCaptureHandler() *captureHandler {
+	return &captureHandler{mu: new(sync.Mutex), records: new([]slog.Record)}
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	r = r.Clone()
+	r.AddAttrs(h.attrs...)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+// recordAttrs retourne les attributs de l'enregistrement de message msg.
+func (h *captureHandler) recordAttrs(t *testing.T, msg string) map[string]slog.Value {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range *h.records {
+		if r.Message == msg {
+			attrs := map[string]slog.Value{}
+			r.Attrs(func(a slog.Attr) bool {
+				attrs[a.Key] = a.Value
+				return true
+			})
+			return attrs
+		}
+	}
+	t.Fatalf("aucun enregistrement %q", msg)
+	return nil
+}
+
+// withCapturedLogs remplace le journal global le temps du test.
+func withCapturedLogs(t *testing.T) *captureHandler {
+	t.Helper()
+	h := newCaptureHandler()
+	saved := logger
+	logger = slog.New(h)
+	t.Cleanup(func() { logger = saved })
+	return h
+}
+
+// serveWithRequestID traite req par requestIDMiddleware et retourne la
+// réponse ainsi que l'identifiant vu dans le contexte par le gestionnaire.
+func serveWithRequestID(header string, req *http.Request) (*httptest.ResponseRecorder, string) {
+	var seen string
+	handler := requestIDMiddleware(header, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = r.Context().Value(requestIDKey{}).(string)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, seen
+}
+
+func TestRequestIDEchoesSuppliedID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	req.Header.Set("X-Request-ID", "trace-42")
+	rec, seen := serveWithRequestID("X-Request-ID", req)
+	if got := rec.Header().Get("X-Request-ID"); got != "trace-42" {
+		t.Errorf("en-tête de réponse %q ; attendu trace-42", got)
+	}
+	if seen != "trace-42" {
+		t.Errorf("identifiant du contexte %q ; attendu trace-42", seen)
+	}
+}
+
+func TestRequestIDGeneratedWhenMissing(t *testing.T) {
+	rec, seen := serveWithRequestID("X-Request-ID", httptest.NewRequest(http.MethodGet, "/livez", nil))
+	got := rec.Header().Get("X-Request-ID")
+	if len(got) != 16 || strings.Trim(got, "0123456789abcdef") != "" {
+		t.Errorf("identifiant généré %q ; attendu 16 caractères hexadécimaux", got)
+	}
+	if seen != got {
+		t.Errorf("identifiant du contexte %q ; attendu celui de la réponse %q", seen, got)
+	}
+	other, _ := serveWithRequestID("X-Request-ID", httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if other.Header().Get("X-Request-ID") == got {
+		t.Error("deux requêtes ont reçu le même identifiant généré")
+	}
+}
+
+func TestRequestIDReplacesInvalidID(t *testing.T) {
+	for _, id := range []string{"avec espace", "ligne\nsuivante", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+		req.Header.Set("X-Request-ID", id)
+		rec, _ := serveWithRequestID("X-Request-ID", req)
+		if got := rec.Header().Get("X-Request-ID"); got == id || len(got) != 16 {
+			t.Errorf("identifiant %q : réponse %q, attendu un identifiant généré", id, got)
+		}
+	}
+}
+
+func TestRequestIDCustomHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	req.Header.Set("X-Correlation-ID", "corr-7")
+	req.Header.Set("X-Request-ID", "ignoré")
+	rec, _ := serveWithRequestID("X-Correlation-ID", req)
+	if got := rec.Header().Get("X-Correlation-ID"); got != "corr-7" {
+		t.Errorf("en-tête personnalisé %q ; attendu corr-7", got)
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "" {
+		t.Errorf("X-Request-ID renvoyé (%q) alors que l'en-tête configuré est X-Correlation-ID", got)
+	}
+}
+
+func TestLoggingMiddlewareRecordsStatus(t *testing.T) {
+	logs := withCapturedLogs(t)
+	handler := requestIDMiddleware("X-Request-ID", loggingMiddleware(http.HandlerFunc(handleSequence)))
+	req := httptest.NewRequest(http.MethodGet, "/sequence?n=-1", nil)
+	req.Header.Set("X-Request-ID", "trace-400")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	attrs := logs.recordAttrs(t, "requête traitée")
+	if got := attrs["status"]; got.Kind() != slog.KindInt64 || got.Int64() != http.StatusBadRequest {
+		t.Errorf("status = %v ; attendu %d", got, http.StatusBadRequest)
+	}
+	if got := attrs["path"].String(); got != "/sequence" {
+		t.Errorf("path = %q ; attendu /sequence", got)
+	}
+	if got := attrs["request_id"].String(); got != "trace-400" {
+		t.Errorf("request_id = %q ; attendu trace-400", got)
+	}
+}
+
+func TestCancelledSequenceLogsN(t *testing.T) {
+	logs := withCapturedLogs(t)
+	handler := requestIDMiddleware("X-Request-ID", loggingMiddleware(http.HandlerFunc(handleSequence)))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/sequence?n=500", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	attrs := logs.recordAttrs(t, "requête annulée")
+	if got := attrs["n"]; got.Kind() != slog.KindInt64 || got.Int64() != 500 {
+		t.Errorf("n = %v ; attendu 500", got)
+	}
+	if _, ok := attrs["request_id"]; !ok {
+		t.Error("request_id absent de l'enregistrement")
+	}
+	if _, ok := logs.recordAttrs(t, "requête traitée")["status"]; !ok {
+		t.Error("status absent de l'enregistrement de la requête")
+	}
+}
@@ -0,0 +1,82 @@
+// Nouvelles tentatives des calculs ayant échoué sur une erreur passagère.
+//
+// Une erreur enveloppée par retryable signale un échec qui peut disparaître
+// de lui-même. runSegment y convertit la panique d'un worker pendant le calcul
+// d'un segment. computeSumWithRetry relance alors le calcul jusqu'à
+// maxRetries fois, en doublant l'attente entre deux tentatives, avant de
+// rendre l'erreur au client. Les autres erreurs (délai dépassé, m hors
+// limites) sont rendues immédiatement.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Attente avant la première nouvelle tentative, puis borne de l'attente.
+const (
+	retryBaseDelay = 100 * time.Millisecond
+	retryMaxDelay  = 2 * time.Second
+)
+
+// maxRetries est le nombre maximal de nouvelles tentatives d'un calcul
+// (option -max-retries, 0 pour n'en faire aucune).
+var maxRetries int
+
+// RetryableError signale une erreur de calcul passagère.
+type RetryableError struct{ Err error }
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+
+// Unwrap retourne l'erreur enveloppée.
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// Cause retourne l'erreur enveloppée, pour errors.Cause.
+func (e *RetryableError) Cause() error { return e.Err }
+
+// retryable enveloppe err dans une RetryableError (nil si err est nil).
+func retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// isRetryable indique si err, ou une erreur qu'elle enveloppe, est passagère.
+func isRetryable(err error) bool {
+	var re *RetryableError
+	return errors.As(err, &re)
+}
+
+// retryDelay retourne l'attente précédant la nouvelle tentative numéro
+// attempt (à partir de 1) : retryBaseDelay, doublée à chaque tentative et
+// bornée par retryMaxDelay.
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// computeSumWithRetry appelle compute, puis le rappelle tant qu'il échoue sur
+// une erreur passagère, dans la limite de retries nouvelles tentatives et du
+// contexte parent.
+func computeSumWithRetry(parent context.Context, config Configuration, retries int,
+	compute func(context.Context, Configuration) (SumResult, error)) (SumResult, error) {
+	res, err := compute(parent, config)
+	for attempt := 1; attempt <= retries && isRetryable(err); attempt++ {
+		delay := retryDelay(attempt)
+		loggerFrom(parent).Warn("nouvelle tentative du calcul", "m", config.M, "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-parent.Done():
+			return res, err
+		}
+		res, err = compute(parent, config)
+	}
+	return res, err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestComputeSumWithRetrySucceedsAfterTwoFailures(t *testing.T) {
+	calls := 0
+	compute := func(ctx context.Context, config Configuration) (SumResult, error) {
+		calls++
+		if calls <= 2 {
+			return SumResult{}, retryable(errors.New("échec passager simulé"))
+		}
+		return SumResult{Sum: big.NewInt(54)}, nil
+	}
+	res, err := computeSumWithRetry(context.Background(), Configuration{M: 10}, 2, compute)
+	if err != nil {
+		t.Fatalf("computeSumWithRetry : %v", err)
+	}
+	if res.Sum.Cmp(big.NewInt(54)) != 0 {
+		t.Errorf("somme = %s, attendu 54", res.Sum)
+	}
+	if calls != 3 {
+		t.Errorf("%d appels, attendu 3", calls)
+	}
+}
+
+func TestComputeSumWithRetryStopsOnPermanentError(t *testing.T) {
+	calls := 0
+	compute := func(ctx context.Context, config Configuration) (SumResult, error) {
+		calls++
+		return SumResult{}, errors.New("m hors limites")
+	}
+	if _, err := computeSumWithRetry(context.Background(), Configuration{}, 3, compute); err == nil {
+		t.Fatal("erreur attendue")
+	}
+	if calls != 1 {
+		t.Errorf("%d appels pour une erreur non passagère, attendu 1", calls)
+	}
+}
+
+func TestComputeSumWithRetryGivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	compute := func(ctx context.Context, config Configuration) (SumResult, error) {
+		calls++
+		return SumResult{}, retryable(errors.New("échec passager simulé"))
+	}
+	if _, err := computeSumWithRetry(context.Background(), Configuration{}, 1, compute); !isRetryable(err) {
+		t.Fatalf("erreur = %v, attendu l'erreur passagère du dernier essai", err)
+	}
+	if calls != 2 {
+		t.Errorf("%d appels, attendu 2", calls)
+	}
+}
+
+func TestIsRetryableThroughWrapping(t *testing.T) {
+	base := retryable(errors.New("allocation refusée"))
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("permanente"), false},
+		{base, true},
+		{errors.Wrap(base, "segment [0, 9]"), true},
+		{errors.WithMessage(errors.Wrap(base, "segment"), "calcul"), true},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%v) = %v, attendu %v", tt.err, got, tt.want)
+		}
+	}
+	if retryable(nil) != nil {
+		t.Error("retryable(nil) doit valoir nil")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	want := []time.Duration{100, 200, 400, 800, 1600, 2000, 2000}
+	for i, w := range want {
+		if got := retryDelay(i + 1); got != w*time.Millisecond {
+			t.Errorf("retryDelay(%d) = %v, attendu %v", i+1, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestRunSegmentTurnsPanicIntoRetryableError(t *testing.T) {
+	empty := &WorkerPool{} // GetCalculator panique faute de calculateur
+	result := runSegment(context.Background(), 0, 9, empty, NewMetrics())
+	if !isRetryable(result.Error) {
+		t.Fatalf("erreur = %v, attendu une erreur passagère", result.Error)
+	}
+	result = runSegment(context.Background(), 0, 9, NewWorkerPool(1), NewMetrics())
+	if result.Error != nil || result.Value.Cmp(big.NewInt(88)) != 0 {
+		t.Errorf("segment [0, 9] = %v, %v ; attendu 88", result.Value, result.Error)
+	}
+}
@@ -0,0 +1,82 @@
+// Préchargement du cache des sommes au démarrage.
+//
+// -seed-cache "10,100,1000" calcule ces sommes pendant le préchauffage, avant
+// que /readyz ne déclare le serveur prêt, afin que les valeurs les plus
+// demandées soient servies depuis le cache dès la première requête routée.
+//
+// Les calculs sont répartis sur un nombre borné de workers. Une entrée
+// invalide ou un calcul en échec est signalé sans interrompre les autres.
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// parseSeedList découpe la liste de valeurs de m séparées par des virgules.
+// Les entrées valides sont retournées dans l'ordre ; chaque entrée invalide
+// produit une erreur distincte.
+func parseSeedList(s string) (ms []int, errs []error) {
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		m, err := strconv.Atoi(field)
+		if err == nil && m < 0 {
+			err = errors.New("m doit être positif ou nul")
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "entrée %q", field))
+			continue
+		}
+		ms = append(ms, m)
+	}
+	return ms, errs
+}
+
+// seedSumCache calcule la somme de chaque valeur de ms avec au plus workers
+// calculs simultanés et l'enregistre dans sumCache. Il retourne le nombre de
+// sommes effectivement enregistrées.
+func seedSumCache(ctx context.Context, ms []int, workers int) int {
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	seeded := 0
+	for _, m := range ms {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			config := DefaultConfig()
+			config.M = m
+			if _, _, err := cachedSum(ctx, config, true); err != nil {
+				logger.Warn("préchargement du cache impossible", "m", m, "error", err)
+				return
+			}
+			mutex.Lock()
+			seeded++
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	return seeded
+}
+
+// seedCache précharge sumCache avec les valeurs de la liste s et journalise
+// les entrées rejetées ainsi que la durée totale du préchargement.
+func seedCache(ctx context.Context, s string, workers int) {
+	ms, errs := parseSeedList(s)
+	for _, err := range errs {
+		logger.Warn("entrée -seed-cache ignorée", "error", err)
+	}
+	start := time.Now()
+	seeded := seedSumCache(ctx, ms, workers)
+	logger.Info("cache préchargé", "seeded", seeded, "requested", len(ms)+len(errs), "duration", time.Since(start))
+}
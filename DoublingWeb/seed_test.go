@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseSeedList(t *testing.T) {
+	ms, errs := parseSeedList(" 10, abc,,-5 ,20")
+	if len(ms) != 2 || ms[0] != 10 || ms[1] != 20 {
+		t.Errorf("valeurs %v ; attendu [10 20]", ms)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), `"abc"`) || !strings.Contains(errs[1].Error(), `"-5"`) {
+		t.Errorf("erreurs %v ; attendu les entrées \"abc\" et \"-5\"", errs)
+	}
+}
+
+func TestSeedCacheSkipsInvalidEntries(t *testing.T) {
+	withSumCache(t, 8)
+	logs := withCapturedLogs(t)
+	seedCache(context.Background(), "10,abc,-5,20", 2)
+
+	for _, m := range []int{10, 20} {
+		if _, ok := sumCache.Get(m); !ok {
+			t.Errorf("m=%d absent du cache malgré les entrées invalides", m)
+		}
+	}
+	logs.mu.Lock()
+	warnings := 0
+	for _, r := range *logs.records {
+		if r.Message == "entrée -seed-cache ignorée" {
+			warnings++
+		}
+	}
+	logs.mu.Unlock()
+	if warnings != 2 {
+		t.Errorf("%d entrée(s) signalée(s) ; attendu 2", warnings)
+	}
+	attrs := logs.recordAttrs(t, "cache préchargé")
+	if seeded, requested := attrs["seeded"].Int64(), attrs["requested"].Int64(); seeded != 2 || requested != 4 {
+		t.Errorf("seeded=%d requested=%d ; attendu 2 et 4", seeded, requested)
+	}
+}
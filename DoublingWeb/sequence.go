@@ -0,0 +1,65 @@
+// Génération de la suite F(0), F(1), …, F(n) pour l'endpoint /sequence.
+//
+// Chaque terme est obtenu par une seule addition des deux précédents, plutôt
+// que par n calculs indépendants.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+)
+
+// maxSequenceN borne l'indice du dernier terme renvoyé par /sequence.
+const maxSequenceN = 10000
+
+// fibSequence émet F(0), F(1), …, F(n) sur le canal retourné, qui est fermé à
+// la fin de la suite ou dès l'annulation de ctx.
+func fibSequence(ctx context.Context, n int) <-chan *big.Int {
+	out := make(chan *big.Int)
+	go func() {
+		defer close(out)
+		a, b := big.NewInt(0), big.NewInt(1) // F(i), F(i+1)
+		for i := 0; i <= n; i++ {
+			select {
+			case out <- new(big.Int).Set(a):
+			case <-ctx.Done():
+				return
+			}
+			a.Add(a, b)
+			a, b = b, a
+		}
+	}()
+	return out
+}
+
+// handleSequence retourne F(0), …, F(n) sous forme de tableau JSON de chaînes
+// décimales (les valeurs dépassent vite la précision des nombres JSON).
+func handleSequence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n < 0 || n > maxSequenceN {
+		http.Error(w, "Paramètre n invalide : entier entre 0 et "+strconv.Itoa(maxSequenceN)+" attendu", http.StatusBadRequest)
+		return
+	}
+
+	values := make([]string, 0, n+1)
+	for v := range fibSequence(r.Context(), n) {
+		values = append(values, v.String())
+	}
+	if err := r.Context().Err(); err != nil {
+		loggerFrom(r.Context()).Warn("requête annulée", "path", r.URL.Path, "n", n, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(values); err != nil {
+		loggerFrom(r.Context()).Error("erreur d'encodage de la réponse", "error", err)
+	}
+}
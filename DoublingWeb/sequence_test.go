@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestFibSequenceMatchesReference(t *testing.T) {
+	const n = 300
+	var got []*big.Int
+	for v := range fibSequence(context.Background(), n) {
+		got = append(got, v)
+	}
+	if len(got) != n+1 {
+		t.Fatalf("%d termes ; attendu %d", len(got), n+1)
+	}
+	// Référence indépendante : F(i+2) = F(i+1) + F(i) et F(2k) = F(k)·(2F(k+1) − F(k)).
+	for i := 2; i <= n; i++ {
+		if sum := new(big.Int).Add(got[i-1], got[i-2]); got[i].Cmp(sum) != 0 {
+			t.Fatalf("F(%d) = %s ; attendu F(%d) + F(%d) = %s", i, got[i], i-1, i-2, sum)
+		}
+	}
+	if got[0].Sign() != 0 || got[1].Cmp(big.NewInt(1)) != 0 || got[93].Text(10) != "12200160415121876738" {
+		t.Errorf("valeurs initiales ou F(93) incorrectes : %s, %s, %s", got[0], got[1], got[93])
+	}
+	for k := 1; 2*k <= n; k++ {
+		double := new(big.Int).Lsh(got[k+1], 1)
+		double.Sub(double, got[k]).Mul(double, got[k])
+		if got[2*k].Cmp(double) != 0 {
+			t.Fatalf("F(%d) = %s ; attendu %s par doublement", 2*k, got[2*k], double)
+		}
+	}
+}
+
+func TestFibSequenceStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seq := fibSequence(ctx, maxSequenceN)
+	for range 3 {
+		<-seq
+	}
+	cancel()
+	// Tant que le destinataire lit, select peut encore choisir l'envoi quelques
+	// fois avant de constater l'annulation ; le canal doit ensuite être fermé.
+	extra := 0
+	for range seq {
+		extra++
+		if extra > 64 {
+			t.Fatal("émission poursuivie après l'annulation")
+		}
+	}
+}
+
+func TestHandleSequence(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleSequence(rec, httptest.NewRequest(http.MethodGet, "/sequence?n=10", nil))
+	var values []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &values); err != nil {
+		t.Fatalf("réponse invalide %q : %v", rec.Body, err)
+	}
+	want := []string{"0", "1", "1", "2", "3", "5", "8", "13", "21", "34", "55"}
+	if len(values) != len(want) {
+		t.Fatalf("/sequence?n=10 = %v ; attendu %v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("terme %d = %s ; attendu %s", i, values[i], want[i])
+		}
+	}
+
+	for _, n := range []string{"-1", strconv.Itoa(maxSequenceN + 1), "dix"} {
+		rec := httptest.NewRecorder()
+		handleSequence(rec, httptest.NewRequest(http.MethodGet, "/sequence?n="+n, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("/sequence?n=%s : code %d ; attendu 400", n, rec.Code)
+		}
+	}
+}
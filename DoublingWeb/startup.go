@@ -0,0 +1,53 @@
+// Erreurs de démarrage du serveur.
+//
+// run retourne une erreur au lieu d'arrêter le processus, et main la restitue
+// sous forme d'objet JSON sur la sortie d'erreur, avec un code de sortie qui
+// distingue une option invalide (2) d'un échec de démarrage (1), par exemple
+// un port déjà occupé. Un superviseur peut ainsi traiter l'échec sans analyser
+// de texte libre :
+//
+//	{"error":"-max-batch-size doit être supérieur ou égal à 1 (reçu 0)","kind":"config"}
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Codes de sortie du serveur.
+const (
+	ExitErrorStartup = 1 // Le serveur n'a pas pu démarrer ou s'est arrêté sur une erreur
+	ExitErrorConfig  = 2 // Une option de la ligne de commande est invalide
+)
+
+// configError signale une option invalide.
+type configError struct{ msg string }
+
+func (e *configError) Error() string { return e.msg }
+
+// configErrorf construit une erreur de configuration à partir d'un format.
+func configErrorf(format string, args ...any) error {
+	return &configError{msg: fmt.Sprintf(format, args...)}
+}
+
+// startupFailure est l'objet JSON écrit lorsque le serveur ne démarre pas.
+type startupFailure struct {
+	Error string `json:"error"` // Description de l'erreur
+	Kind  string `json:"kind"`  // "config" (option invalide) ou "startup"
+}
+
+// reportStartupError écrit err sur w sous forme d'objet JSON et retourne le
+// code de sortie correspondant.
+func reportStartupError(w io.Writer, err error) int {
+	failure, code := startupFailure{Error: err.Error(), Kind: "startup"}, ExitErrorStartup
+	var cfgErr *configError
+	if errors.As(err, &cfgErr) {
+		failure.Kind, code = "config", ExitErrorConfig
+	}
+	json.NewEncoder(w).Encode(failure)
+	return code
+}
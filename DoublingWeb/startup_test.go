@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenInvalidPortReturnsError(t *testing.T) {
+	listener, err := listen(":99999", "")
+	if err == nil {
+		listener.Close()
+		t.Fatal("listen(\":99999\") : erreur attendue")
+	}
+}
+
+func TestReportStartupError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind string
+		wantCode int
+	}{
+		{"config", configErrorf("-max-batch-size doit être supérieur ou égal à 1 (reçu %d)", 0), "config", ExitErrorConfig},
+		{"startup", errors.New("adresse déjà utilisée"), "startup", ExitErrorStartup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			code := reportStartupError(&buf, tt.err)
+			if code != tt.wantCode {
+				t.Errorf("code = %d ; attendu %d", code, tt.wantCode)
+			}
+			var failure startupFailure
+			if err := json.Unmarshal(buf.Bytes(), &failure); err != nil {
+				t.Fatalf("sortie non JSON %q : %v", buf.String(), err)
+			}
+			if failure.Kind != tt.wantKind || failure.Error != tt.err.Error() {
+				t.Errorf("objet = %+v ; attendu kind %q et error %q", failure, tt.wantKind, tt.err)
+			}
+		})
+	}
+}
+
+// TestRunStartupFailures lance le serveur dans un processus fils et vérifie
+// que l'échec est restitué en JSON sur la sortie d'erreur, avec le bon code.
+func TestRunStartupFailures(t *testing.T) {
+	if args := os.Getenv("FIB_WEB_STARTUP_ARGS"); args != "" {
+		os.Args = append([]string{"DoublingWeb"}, strings.Fields(args)...)
+		main()
+		return
+	}
+	notSocket := filepath.Join(t.TempDir(), "fichier")
+	if err := os.WriteFile(notSocket, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		args     string
+		wantKind string
+		wantCode int
+	}{
+		{"option invalide", "-max-batch-size 0", "config", ExitErrorConfig},
+		{"écoute impossible", "-socket " + notSocket, "startup", ExitErrorStartup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestRunStartupFailures$")
+			cmd.Env = append(os.Environ(), "FIB_WEB_STARTUP_ARGS="+tt.args)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != tt.wantCode {
+				t.Fatalf("processus fils : %v ; attendu le code %d\n%s", err, tt.wantCode, stderr.String())
+			}
+			var failure startupFailure
+			if err := json.Unmarshal(stderr.Bytes(), &failure); err != nil {
+				t.Fatalf("sortie d'erreur non JSON %q : %v", stderr.String(), err)
+			}
+			if failure.Kind != tt.wantKind || failure.Error == "" {
+				t.Errorf("objet = %+v ; attendu kind %q et un message", failure, tt.wantKind)
+			}
+		})
+	}
+}
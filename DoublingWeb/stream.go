@@ -0,0 +1,144 @@
+// Transmission en flux du résultat complet en décimal.
+//
+// Pour de très grandes sommes, construire la chaîne décimale complète puis la
+// réponse JSON est coûteux en mémoire. En mode ?format=stream, la somme est
+// convertie par blocs (diviser pour régner par puissances de 10) et chaque bloc
+// est écrit puis vidé vers le client ; net/http utilise alors automatiquement
+// le transfert chunked puisque la taille totale n'est pas annoncée.
+//
+// Avec ?checksum=sha256, l'empreinte SHA-256 des chiffres transmis (sans le
+// saut de ligne final) est calculée au fil de l'écriture et envoyée dans le
+// trailer X-Result-SHA256, en hexadécimal : le client peut vérifier l'intégrité
+// du résultat sans que le serveur relise les chiffres.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// decimalLeafBits est la taille (en bits) en dessous de laquelle un bloc est
+// converti directement avec big.Int.Text(10) (environ 10 000 chiffres).
+const decimalLeafBits = 32768
+
+// checksumTrailer est le trailer portant l'empreinte SHA-256 du flux.
+const checksumTrailer = "X-Result-SHA256"
+
+// wantChecksum indique si la requête demande l'empreinte du flux
+// (?checksum=sha256), seul algorithme accepté.
+func wantChecksum(r *http.Request) (bool, error) {
+	switch algo := r.URL.Query().Get("checksum"); algo {
+	case "":
+		return false, nil
+	case "sha256":
+		return true, nil
+	default:
+		return false, fmt.Errorf("checksum non pris en charge %q (attendu : sha256)", algo)
+	}
+}
+
+// flushWriter écrit vers la réponse HTTP en vidant le tampon après chaque bloc,
+// et interrompt l'écriture dès que le contexte de la requête est annulé.
+type flushWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	flusher http.Flusher
+}
+
+// Write écrit p puis vide le tampon de la réponse, sauf si la requête est annulée.
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	if err := fw.ctx.Err(); err != nil {
+		return 0, err // Le client s'est déconnecté : arrêter le flux
+	}
+	n, err := fw.w.Write(p)
+	if fw.flusher != nil {
+		fw.flusher.Flush()
+	}
+	return n, err
+}
+
+// streamSum calcule la somme puis l'écrit en décimal, bloc par bloc, dans la
+// réponse. Si checksum est vrai, l'empreinte des chiffres est envoyée en trailer.
+func streamSum(w http.ResponseWriter, r *http.Request, config Configuration, checksum bool) {
+	res, hit, err := cachedSum(r.Context(), config, useCache(r))
+	if err != nil {
+		loggerFrom(r.Context()).Error("échec du calcul", "m", config.M, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setCacheHeader(w, r, hit)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	flusher, _ := w.(http.Flusher)
+	fw := &flushWriter{ctx: r.Context(), w: w, flusher: flusher}
+	var digits io.Writer = fw
+	hash := sha256.New()
+	if checksum {
+		w.Header().Set("Trailer", checksumTrailer) // Annoncé avant le corps
+		digits = io.MultiWriter(fw, hash)
+	}
+	if err := writeDecimal(digits, res.Sum); err != nil {
+		loggerFrom(r.Context()).Warn("flux interrompu", "m", config.M, "error", err)
+		return
+	}
+	io.WriteString(fw, "\n")
+	if checksum {
+		w.Header().Set(checksumTrailer, hex.EncodeToString(hash.Sum(nil)))
+	}
+}
+
+// writeDecimal écrit la représentation décimale de v sur w, bloc par bloc.
+func writeDecimal(w io.Writer, v *big.Int) error {
+	if v.Sign() < 0 {
+		if _, err := io.WriteString(w, "-"); err != nil {
+			return err
+		}
+		v = new(big.Int).Abs(v)
+	}
+	if v.BitLen() <= decimalLeafBits {
+		_, err := io.WriteString(w, v.Text(10))
+		return err
+	}
+
+	// Découpage en deux moitiés d'environ le même nombre de chiffres
+	digits := int(float64(v.BitLen())*math.Log10(2)) + 1
+	low := digits / 2
+	high, rest := new(big.Int).QuoRem(v, pow10(low), new(big.Int))
+	if err := writeDecimal(w, high); err != nil {
+		return err
+	}
+	return writeDecimalPadded(w, rest, low)
+}
+
+// writeDecimalPadded écrit v sur exactement width chiffres, complété à gauche
+// par des zéros. v doit être strictement inférieur à 10^width.
+func writeDecimalPadded(w io.Writer, v *big.Int, width int) error {
+	if v.BitLen() <= decimalLeafBits {
+		s := v.Text(10)
+		if _, err := io.WriteString(w, strings.Repeat("0", width-len(s))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, s)
+		return err
+	}
+
+	low := width / 2
+	high, rest := new(big.Int).QuoRem(v, pow10(low), new(big.Int))
+	if err := writeDecimalPadded(w, high, width-low); err != nil {
+		return err
+	}
+	return writeDecimalPadded(w, rest, low)
+}
+
+// pow10 retourne 10^k.
+func pow10(k int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(k)), nil)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// streamServer démarre un serveur de test exposant /fibonacci.
+func streamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(handleFibonacci))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// postStream envoie {"m": m} à /fibonacci avec la requête query et retourne la
+// réponse, dont le corps a été entièrement lu (trailers compris).
+func postStream(t *testing.T, srv *httptest.Server, query string, m int) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Post(srv.URL+"/fibonacci?"+query, "application/json", strings.NewReader(fmt.Sprintf(`{"m": %d}`, m)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestWriteDecimal(t *testing.T) {
+	pow := func(k int64) *big.Int { return new(big.Int).Exp(big.NewInt(10), big.NewInt(k), nil) }
+	large, _ := NewFibCalculator().Calculate(100000) // Plusieurs niveaux de découpage
+	tests := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(7),
+		big.NewInt(-12345),
+		pow(20000), // Moitié basse entièrement nulle
+		new(big.Int).Sub(pow(20000), big.NewInt(1)), // Que des 9
+		new(big.Int).Add(pow(30000), big.NewInt(1)), // Zéros de remplissage entre les blocs
+		new(big.Int).Neg(new(big.Int).Add(pow(25000), big.NewInt(42))),
+		large,
+	}
+	for _, v := range tests {
+		var sb strings.Builder
+		if err := writeDecimal(&sb, v); err != nil {
+			t.Fatalf("writeDecimal : %v", err)
+		}
+		if want := v.Text(10); sb.String() != want {
+			t.Errorf("writeDecimal d'un nombre de %d chiffres : sortie différente de Text(10)", len(want))
+		}
+	}
+}
+
+func TestStreamSumParsesBackToSum(t *testing.T) {
+	srv := streamServer(t)
+	// Les grands nombres sont découpés en blocs par TestWriteDecimal ; ici, des
+	// valeurs de m modestes suffisent à vérifier le transport de bout en bout.
+	for _, m := range []int{2, 10, 5000, 12000} {
+		resp, body := postStream(t, srv, "format=stream&nocache=1", m)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("m=%d : code %d (%s)", m, resp.StatusCode, body)
+		}
+		if len(resp.TransferEncoding) == 0 || resp.TransferEncoding[0] != "chunked" {
+			t.Errorf("m=%d : Transfer-Encoding = %v, attendu chunked", m, resp.TransferEncoding)
+		}
+		got, ok := new(big.Int).SetString(strings.TrimSuffix(body, "\n"), 10)
+		if !ok {
+			t.Fatalf("m=%d : corps non décimal", m)
+		}
+		want, err := NewFibCalculator().PrefixSum(m - 1) // F(0) + … + F(m-2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("m=%d : somme relue différente de la somme calculée", m)
+		}
+	}
+}
+
+func TestStreamChecksumTrailer(t *testing.T) {
+	srv := streamServer(t)
+	for _, m := range []int{2, 10, 5000} {
+		resp, body := postStream(t, srv, "format=stream&checksum=sha256&nocache=1", m)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("m=%d : code %d (%s)", m, resp.StatusCode, body)
+		}
+		digits := strings.TrimSuffix(body, "\n")
+		sum := sha256.Sum256([]byte(digits))
+		if got, want := resp.Trailer.Get(checksumTrailer), hex.EncodeToString(sum[:]); got != want {
+			t.Errorf("m=%d : trailer %s = %q, attendu %q", m, checksumTrailer, got, want)
+		}
+	}
+}
+
+func TestStreamChecksumRejectsInvalidRequests(t *testing.T) {
+	srv := streamServer(t)
+	for _, query := range []string{
+		"checksum=sha256",            // Sans format=stream
+		"format=stream&checksum=md5", // Algorithme inconnu
+	} {
+		if resp, body := postStream(t, srv, query, 10); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("?%s : code %d (%s), attendu 400", query, resp.StatusCode, body)
+		}
+	}
+}
@@ -3,14 +3,17 @@ package main
 
 // Import des bibliothèques nécessaires
 import (
-	"context"  // Pour gérer les contextes et les timeouts
-	"fmt"      // Pour l'affichage formaté
-	"log"      // Pour la journalisation des erreurs
-	"math/big" // Pour gérer les très grands nombres
-	"runtime"  // Pour obtenir des informations sur l'environnement d'exécution
-	"strings"  // Pour manipuler les chaînes de caractères
-	"sync"     // Pour la synchronisation des goroutines
-	"time"     // Pour mesurer le temps et gérer les timeouts
+	"context"       // Pour gérer les contextes et les timeouts
+	"encoding/json" // Pour exporter les métriques au format JSON
+	"flag"          // Pour lire les options de la ligne de commande
+	"fmt"           // Pour l'affichage formaté
+	"log"           // Pour la journalisation des erreurs
+	"math/big"      // Pour gérer les très grands nombres
+	"os"            // Pour écrire le fichier de métriques
+	"runtime"       // Pour obtenir des informations sur l'environnement d'exécution
+	"strings"       // Pour manipuler les chaînes de caractères
+	"sync"          // Pour la synchronisation des goroutines
+	"time"          // Pour mesurer le temps et gérer les timeouts
 
 	"github.com/pkg/errors" // Pour une meilleure gestion des erreurs
 )
@@ -22,8 +25,24 @@ type Configuration struct {
 	NumWorkers  int           // Nombre de goroutines de calcul parallèles
 	SegmentSize int           // Nombre de calculs par segment pour chaque worker
 	Timeout     time.Duration // Temps maximum autorisé pour l'ensemble des calculs
+	MetricsJSON string        // Fichier où exporter les métriques au format JSON (optionnel)
+	Algorithm   string        // Multiplication matricielle : "classique" ou "strassen"
+
+	// Taille (en bits) des éléments à partir de laquelle la multiplication
+	// de Strassen est utilisée ; en dessous, on revient à la méthode classique
+	StrassenThreshold int
+
+	// Taille (en bits) des éléments à partir de laquelle les quatre éléments
+	// d'un produit classique sont calculés en parallèle ; négatif pour désactiver
+	ParallelThreshold int
 }
 
+// Algorithmes de multiplication matricielle disponibles
+const (
+	AlgorithmClassic  = "classique" // 8 multiplications par produit de matrices
+	AlgorithmStrassen = "strassen"  // 7 multiplications par produit de matrices
+)
+
 // DefaultConfig retourne une configuration par défaut avec des valeurs optimisées
 func DefaultConfig() Configuration {
 	return Configuration{
@@ -31,6 +50,10 @@ func DefaultConfig() Configuration {
 		NumWorkers:  runtime.NumCPU(), // Utilise tous les processeurs disponibles
 		SegmentSize: 1000,             // Chaque worker traite 1000 nombres à la fois
 		Timeout:     5 * time.Minute,  // Arrête le calcul après 5 minutes
+		Algorithm:   AlgorithmClassic, // Multiplication matricielle classique
+
+		StrassenThreshold: 4096,  // Strassen au-delà de 4096 bits par élément
+		ParallelThreshold: 65536, // Produit parallèle au-delà de 65536 bits par élément
 	}
 }
 
@@ -40,6 +63,8 @@ type Metrics struct {
 	StartTime         time.Time  // Moment où le calcul commence
 	EndTime           time.Time  // Moment où le calcul se termine
 	TotalCalculations int64      // Nombre total de nombres de Fibonacci calculés
+	Segments          int64      // Nombre de segments terminés avec succès
+	Errors            int64      // Nombre de segments terminés en erreur
 	mutex             sync.Mutex // Verrou pour protéger les modifications concurrentes
 }
 
@@ -53,6 +78,62 @@ func (m *Metrics) IncrementCalculations(count int64) {
 	m.mutex.Lock()         // Verrouille l'accès aux données
 	defer m.mutex.Unlock() // Déverrouille à la fin de la fonction
 	m.TotalCalculations += count
+	m.Segments++
+}
+
+// IncrementErrors comptabilise un segment terminé en erreur
+func (m *Metrics) IncrementErrors() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Errors++
+}
+
+// MetricsReport est la représentation JSON des métriques d'une exécution
+type MetricsReport struct {
+	M                 int       `json:"m"`                 // Limite supérieure (exclue) du calcul
+	NumWorkers        int       `json:"numWorkers"`        // Nombre de workers utilisés
+	SegmentSize       int       `json:"segmentSize"`       // Taille des segments
+	Algorithm         string    `json:"algorithm"`         // Algorithme de multiplication utilisé
+	StartTime         time.Time `json:"startTime"`         // Début du calcul
+	EndTime           time.Time `json:"endTime"`           // Fin du calcul
+	DurationNs        int64     `json:"durationNs"`        // Durée totale en nanosecondes
+	TotalCalculations int64     `json:"totalCalculations"` // Nombre de termes calculés
+	Segments          int64     `json:"segments"`          // Nombre de segments terminés avec succès
+	Errors            int64     `json:"errors"`            // Nombre de segments en erreur
+	AverageTimeNs     int64     `json:"averageTimeNs"`     // Temps moyen par calcul en nanosecondes
+}
+
+// Report construit un instantané des métriques pour la configuration donnée
+func (m *Metrics) Report(config Configuration) MetricsReport {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	duration := m.EndTime.Sub(m.StartTime)
+	report := MetricsReport{
+		M:                 config.M,
+		NumWorkers:        config.NumWorkers,
+		SegmentSize:       config.SegmentSize,
+		Algorithm:         config.Algorithm,
+		StartTime:         m.StartTime,
+		EndTime:           m.EndTime,
+		DurationNs:        duration.Nanoseconds(),
+		TotalCalculations: m.TotalCalculations,
+		Segments:          m.Segments,
+		Errors:            m.Errors,
+	}
+	if m.TotalCalculations > 0 {
+		report.AverageTimeNs = duration.Nanoseconds() / m.TotalCalculations
+	}
+	return report
+}
+
+// writeMetricsJSON écrit le rapport de métriques dans le fichier path
+func writeMetricsJSON(path string, report MetricsReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encodage des métriques")
+	}
+	return errors.Wrapf(os.WriteFile(path, append(data, '\n'), 0o644), "écriture de %s", path)
 }
 
 // Matrix2x2 représente une matrice 2x2 utilisée pour le calcul de Fibonacci
@@ -80,8 +161,18 @@ type FibCalculator struct {
 	tempMatrix *Matrix2x2 // Matrice temporaire pour les calculs
 	powMatrix  *Matrix2x2 // Matrice résultat de l'exponentiation
 	mutex      sync.Mutex // Protection pour l'accès concurrent
+
+	// Seuil (en bits) à partir duquel Strassen est utilisé ; négatif si désactivé
+	strassenThreshold int
+
+	// Seuil (en bits) à partir duquel le produit classique est parallélisé ; négatif si désactivé
+	parallelThreshold int
 }
 
+// mulSlots borne le nombre de goroutines de multiplication lancées par
+// l'ensemble des calculateurs, pour ne pas surcharger les processeurs
+var mulSlots = make(chan struct{}, runtime.NumCPU())
+
 // NewFibCalculator initialise un nouveau calculateur de Fibonacci
 func NewFibCalculator() *FibCalculator {
 	fc := &FibCalculator{
@@ -89,6 +180,9 @@ func NewFibCalculator() *FibCalculator {
 		baseMatrix: NewMatrix2x2(),
 		tempMatrix: NewMatrix2x2(),
 		powMatrix:  NewMatrix2x2(),
+
+		strassenThreshold: -1,
+		parallelThreshold: -1,
 	}
 
 	// Initialise la matrice de base [[1,1],[1,0]]
@@ -101,9 +195,32 @@ func NewFibCalculator() *FibCalculator {
 	return fc
 }
 
+// NewStrassenCalculator initialise un calculateur qui multiplie les matrices
+// avec l'algorithme de Strassen dès que leurs éléments dépassent threshold bits
+func NewStrassenCalculator(threshold int) *FibCalculator {
+	fc := NewFibCalculator()
+	fc.strassenThreshold = max(threshold, 0)
+	return fc
+}
+
+// bitLen retourne la taille en bits du plus grand élément de la matrice
+func (m *Matrix2x2) bitLen() int {
+	return max(m.a11.BitLen(), m.a12.BitLen(), m.a21.BitLen(), m.a22.BitLen())
+}
+
 // multiplyMatrices multiplie deux matrices 2x2
 // Le résultat est stocké dans la matrice result
 func (fc *FibCalculator) multiplyMatrices(m1, m2, result *Matrix2x2) {
+	bits := max(m1.bitLen(), m2.bitLen())
+	if fc.strassenThreshold >= 0 && bits >= fc.strassenThreshold {
+		fc.multiplyStrassen(m1, m2, result)
+		return
+	}
+	if fc.parallelThreshold >= 0 && bits >= fc.parallelThreshold && runtime.GOMAXPROCS(0) > 1 {
+		fc.multiplyParallel(m1, m2, result)
+		return
+	}
+
 	temp1 := new(big.Int) // Variables temporaires pour
 	temp2 := new(big.Int) // éviter les allocations répétées
 
@@ -131,6 +248,95 @@ func (fc *FibCalculator) multiplyMatrices(m1, m2, result *Matrix2x2) {
 	result.a22.Add(temp1, temp2)
 }
 
+// multiplyParallel multiplie deux matrices 2x2 comme multiplyMatrices, mais
+// calcule les quatre éléments du résultat dans des goroutines distinctes.
+// Si result est m1 ou m2, les éléments sont produits dans des big.Int neufs
+// puis recopiés une fois tous les calculs terminés. Lorsqu'aucune place n'est
+// libre dans mulSlots, l'élément est calculé dans la goroutine appelante.
+func (fc *FibCalculator) multiplyParallel(m1, m2, result *Matrix2x2) {
+	// entry calcule dst = x1*y1 + x2*y2
+	entry := func(dst, x1, y1, x2, y2 *big.Int) {
+		t := new(big.Int).Mul(x2, y2)
+		dst.Mul(x1, y1)
+		dst.Add(dst, t)
+	}
+	operands := [4][4]*big.Int{
+		{m1.a11, m2.a11, m1.a12, m2.a21}, // result[1,1]
+		{m1.a11, m2.a12, m1.a12, m2.a22}, // result[1,2]
+		{m1.a21, m2.a11, m1.a22, m2.a21}, // result[2,1]
+		{m1.a21, m2.a12, m1.a22, m2.a22}, // result[2,2]
+	}
+
+	aliased := result == m1 || result == m2
+	entries := [4]*big.Int{result.a11, result.a12, result.a21, result.a22}
+	if aliased {
+		for i := range entries {
+			entries[i] = new(big.Int)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, op := range operands {
+		select {
+		case mulSlots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer func() { <-mulSlots; wg.Done() }()
+				entry(entries[i], op[0], op[1], op[2], op[3])
+			}()
+		default:
+			entry(entries[i], op[0], op[1], op[2], op[3])
+		}
+	}
+	wg.Wait()
+
+	if aliased {
+		result.a11.Set(entries[0])
+		result.a12.Set(entries[1])
+		result.a21.Set(entries[2])
+		result.a22.Set(entries[3])
+	}
+}
+
+// multiplyStrassen multiplie deux matrices 2x2 avec l'algorithme de Strassen :
+// 7 produits de grands entiers au lieu de 8, au prix de 18 additions.
+// Le résultat est stocké dans la matrice result, qui peut être m1 ou m2.
+func (fc *FibCalculator) multiplyStrassen(m1, m2, result *Matrix2x2) {
+	s := new(big.Int) // Sommes intermédiaires
+	t := new(big.Int) // sur les éléments de m1 et m2
+
+	// p1 = (a11 + a22)(b11 + b22)
+	p1 := new(big.Int).Mul(s.Add(m1.a11, m1.a22), t.Add(m2.a11, m2.a22))
+	// p2 = (a21 + a22) b11
+	p2 := new(big.Int).Mul(s.Add(m1.a21, m1.a22), m2.a11)
+	// p3 = a11 (b12 - b22)
+	p3 := new(big.Int).Mul(m1.a11, t.Sub(m2.a12, m2.a22))
+	// p4 = a22 (b21 - b11)
+	p4 := new(big.Int).Mul(m1.a22, t.Sub(m2.a21, m2.a11))
+	// p5 = (a11 + a12) b22
+	p5 := new(big.Int).Mul(s.Add(m1.a11, m1.a12), m2.a22)
+	// p6 = (a21 - a11)(b11 + b12)
+	p6 := new(big.Int).Mul(s.Sub(m1.a21, m1.a11), t.Add(m2.a11, m2.a12))
+	// p7 = (a12 - a22)(b21 + b22)
+	p7 := new(big.Int).Mul(s.Sub(m1.a12, m1.a22), t.Add(m2.a21, m2.a22))
+
+	// result[1,1] = p1 + p4 - p5 + p7
+	result.a11.Add(p1, p4)
+	result.a11.Sub(result.a11, p5)
+	result.a11.Add(result.a11, p7)
+
+	// result[1,2] = p3 + p5
+	result.a12.Add(p3, p5)
+
+	// result[2,1] = p2 + p4
+	result.a21.Add(p2, p4)
+
+	// result[2,2] = p1 - p2 + p3 + p6
+	result.a22.Sub(p1, p2)
+	result.a22.Add(result.a22, p3)
+	result.a22.Add(result.a22, p6)
+}
+
 // matrixPower calcule la puissance n-ième de la matrice de base
 // Utilise l'algorithme d'exponentiation rapide (complexity O(log n))
 func (fc *FibCalculator) matrixPower(n int) {
@@ -198,10 +404,16 @@ type WorkerPool struct {
 }
 
 // NewWorkerPool crée un nouveau pool de calculateurs
-func NewWorkerPool(size int) *WorkerPool {
+// en utilisant l'algorithme de multiplication choisi dans la configuration
+func NewWorkerPool(size int, config Configuration) *WorkerPool {
 	calculators := make([]*FibCalculator, size)
 	for i := range calculators {
-		calculators[i] = NewFibCalculator()
+		if config.Algorithm == AlgorithmStrassen {
+			calculators[i] = NewStrassenCalculator(config.StrassenThreshold)
+		} else {
+			calculators[i] = NewFibCalculator()
+		}
+		calculators[i].parallelThreshold = config.ParallelThreshold
 	}
 	return &WorkerPool{
 		calculators: calculators,
@@ -269,19 +481,14 @@ func formatBigIntSci(n *big.Int) string {
 	return fmt.Sprintf("%se%d", formattedNum, exponent)
 }
 
-// main est le point d'entrée du programme
-func main() {
-	// Initialisation
-	config := DefaultConfig()
-	metrics := NewMetrics()
+// computeSum calcule la somme F(0) + … + F(config.M-2) en répartissant les indices
+// en segments de config.SegmentSize, chacun traité par sa propre goroutine.
+// Les segments en erreur sont journalisés, comptés dans metrics et exclus de la somme
+func computeSum(ctx context.Context, config Configuration, metrics *Metrics) *big.Int {
 	n := config.M - 1
 
-	// Crée un contexte avec timeout
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
-	defer cancel()
-
 	// Initialise le pool de workers et les canaux
-	pool := NewWorkerPool(config.NumWorkers)
+	pool := NewWorkerPool(config.NumWorkers, config)
 	results := make(chan Result, config.NumWorkers)
 	var wg sync.WaitGroup
 
@@ -314,6 +521,7 @@ func main() {
 	for result := range results {
 		if result.Error != nil {
 			log.Printf("Erreur durant le calcul: %v", result.Error)
+			metrics.IncrementErrors()
 			hasErrors = true
 			continue
 		}
@@ -323,6 +531,28 @@ func main() {
 	if hasErrors {
 		log.Printf("Des erreurs sont survenues pendant le calcul")
 	}
+	return sumFib
+}
+
+// main est le point d'entrée du programme
+func main() {
+	// Initialisation
+	config := DefaultConfig()
+	flag.StringVar(&config.MetricsJSON, "metrics-json", "", "Fichier où exporter les métriques de l'exécution au format JSON")
+	flag.StringVar(&config.Algorithm, "algo", config.Algorithm, "Multiplication matricielle : classique ou strassen")
+	flag.IntVar(&config.StrassenThreshold, "strassen-threshold", config.StrassenThreshold, "Taille en bits des éléments à partir de laquelle Strassen est utilisé")
+	flag.IntVar(&config.ParallelThreshold, "parallel-threshold", config.ParallelThreshold, "Taille en bits des éléments à partir de laquelle le produit classique est parallélisé (négatif pour désactiver)")
+	flag.Parse()
+	if config.Algorithm != AlgorithmClassic && config.Algorithm != AlgorithmStrassen {
+		log.Fatalf("Algorithme inconnu: %q (classique ou strassen)", config.Algorithm)
+	}
+	metrics := NewMetrics()
+
+	// Crée un contexte avec timeout
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	sumFib := computeSum(ctx, config, metrics)
 
 	// Calcul et affichage des métriques finales
 	metrics.EndTime = time.Now()
@@ -334,6 +564,12 @@ func main() {
 	fmt.Printf("  Nombre de workers: %d\n", config.NumWorkers)
 	fmt.Printf("  Taille des segments: %d\n", config.SegmentSize)
 	fmt.Printf("  Valeur de m: %d\n", config.M)
+	fmt.Printf("  Multiplication: %s\n", config.Algorithm)
+	if config.Algorithm == AlgorithmStrassen {
+		fmt.Printf("  Seuil de Strassen: %d bits\n", config.StrassenThreshold)
+	} else if config.ParallelThreshold >= 0 {
+		fmt.Printf("  Seuil de parallélisation: %d bits\n", config.ParallelThreshold)
+	}
 
 	fmt.Printf("\nPerformance:\n")
 	fmt.Printf("  Temps total d'exécution: %v\n", duration)
@@ -342,4 +578,12 @@ func main() {
 
 	fmt.Printf("\nRésultat:\n")
 	fmt.Printf("  Somme des Fibonacci(0..%d): %s\n", config.M, formatBigIntSci(sumFib))
+
+	// Exporte les métriques si demandé
+	if config.MetricsJSON != "" {
+		if err := writeMetricsJSON(config.MetricsJSON, metrics.Report(config)); err != nil {
+			log.Fatalf("Erreur lors de l'export des métriques: %v", err)
+		}
+		fmt.Printf("\nMétriques exportées dans %s\n", config.MetricsJSON)
+	}
 }
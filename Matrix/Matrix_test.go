@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// randomMatrix retourne une matrice d'éléments signés d'environ bits bits.
+func randomMatrix(r *rand.Rand, bits int) *Matrix2x2 {
+	m := NewMatrix2x2()
+	for _, e := range []*big.Int{m.a11, m.a12, m.a21, m.a22} {
+		words := make([]big.Word, (bits+63)/64)
+		for i := range words {
+			words[i] = big.Word(r.Uint64())
+		}
+		e.SetBits(words)
+		if r.IntN(2) == 0 {
+			e.Neg(e)
+		}
+	}
+	return m
+}
+
+// copyMatrix retourne une copie indépendante de m.
+func copyMatrix(m *Matrix2x2) *Matrix2x2 {
+	c := NewMatrix2x2()
+	c.a11.Set(m.a11)
+	c.a12.Set(m.a12)
+	c.a21.Set(m.a21)
+	c.a22.Set(m.a22)
+	return c
+}
+
+// equalMatrix indique si a et b ont les mêmes éléments.
+func equalMatrix(a, b *Matrix2x2) bool {
+	return a.a11.Cmp(b.a11) == 0 && a.a12.Cmp(b.a12) == 0 && a.a21.Cmp(b.a21) == 0 && a.a22.Cmp(b.a22) == 0
+}
+
+// checkMultiply compare multiply au produit classique, avec un résultat
+// distinct des opérandes puis confondu avec m1, avec m2 et avec les deux.
+func checkMultiply(t *testing.T, multiply func(m1, m2, result *Matrix2x2)) {
+	t.Helper()
+	classic := NewFibCalculator()
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, bits := range []int{1, 64, 1000, 20000} {
+		m1, m2 := randomMatrix(r, bits), randomMatrix(r, bits)
+		want := NewMatrix2x2()
+		classic.multiplyMatrices(m1, m2, want)
+		square := NewMatrix2x2()
+		classic.multiplyMatrices(m1, m1, square)
+
+		got := NewMatrix2x2()
+		multiply(m1, m2, got)
+		if !equalMatrix(got, want) {
+			t.Errorf("%d bits : produit différent du produit classique", bits)
+		}
+		a := copyMatrix(m1)
+		multiply(a, m2, a)
+		if !equalMatrix(a, want) {
+			t.Errorf("%d bits, résultat confondu avec m1 : produit incorrect", bits)
+		}
+		b := copyMatrix(m2)
+		multiply(m1, b, b)
+		if !equalMatrix(b, want) {
+			t.Errorf("%d bits, résultat confondu avec m2 : produit incorrect", bits)
+		}
+		s := copyMatrix(m1)
+		multiply(s, s, s)
+		if !equalMatrix(s, square) {
+			t.Errorf("%d bits, carré en place : produit incorrect", bits)
+		}
+	}
+}
+
+// checkCalculate compare fc.Calculate au calcul classique pour n jusqu'à 100 000.
+func checkCalculate(t *testing.T, fc *FibCalculator) {
+	t.Helper()
+	classic := NewFibCalculator()
+	for _, n := range []int{0, 1, 2, 3, 10, 93, 94, 1000, 4097, 10000, 65537, 100000} {
+		want, err := classic.Calculate(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := fc.Calculate(n)
+		if err != nil {
+			t.Fatalf("Calculate(%d) : %v", n, err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("Calculate(%d) différent du calcul classique", n)
+		}
+	}
+}
+
+func TestMultiplyStrassenMatchesClassic(t *testing.T) {
+	checkMultiply(t, NewStrassenCalculator(0).multiplyStrassen)
+}
+
+func TestStrassenCalculatorMatchesClassic(t *testing.T) {
+	for _, threshold := range []int{0, 4096} { // Strassen partout, ou au-delà du seuil par défaut
+		checkCalculate(t, NewStrassenCalculator(threshold))
+	}
+}
+
+func TestWorkerPoolUsesConfiguredAlgorithm(t *testing.T) {
+	config := DefaultConfig()
+	config.Algorithm = AlgorithmStrassen
+	config.StrassenThreshold = 128
+	pool := NewWorkerPool(2, config)
+	for _, fc := range pool.calculators {
+		if fc.strassenThreshold != 128 {
+			t.Errorf("seuil de Strassen = %d, attendu 128", fc.strassenThreshold)
+		}
+	}
+	config.Algorithm = AlgorithmClassic
+	if fc := NewWorkerPool(1, config).GetCalculator(); fc.strassenThreshold >= 0 {
+		t.Errorf("algorithme classique : seuil de Strassen %d, attendu désactivé", fc.strassenThreshold)
+	}
+}
+
+// parallelCalculator retourne un calculateur qui parallélise tous les produits.
+func parallelCalculator() *FibCalculator {
+	fc := NewFibCalculator()
+	fc.parallelThreshold = 0
+	return fc
+}
+
+// withMaxProcs fixe GOMAXPROCS à au moins 4 pendant le test : en dessous de 2,
+// multiplyMatrices n'emprunte jamais le chemin parallèle.
+func withMaxProcs(t testing.TB) {
+	prev := runtime.GOMAXPROCS(max(runtime.GOMAXPROCS(0), 4))
+	t.Cleanup(func() { runtime.GOMAXPROCS(prev) })
+}
+
+func TestMultiplyParallelMatchesSequential(t *testing.T) {
+	checkMultiply(t, parallelCalculator().multiplyParallel)
+}
+
+func TestMultiplyParallelWithoutFreeSlots(t *testing.T) {
+	// Toutes les places occupées : chaque élément est calculé dans l'appelant.
+	for range cap(mulSlots) {
+		mulSlots <- struct{}{}
+	}
+	defer func() {
+		for range cap(mulSlots) {
+			<-mulSlots
+		}
+	}()
+	checkMultiply(t, parallelCalculator().multiplyParallel)
+}
+
+func TestParallelCalculatorMatchesSequential(t *testing.T) {
+	withMaxProcs(t)
+	checkCalculate(t, parallelCalculator())
+}
+
+func TestParallelCalculatorsShareSlots(t *testing.T) {
+	// Plusieurs calculateurs simultanés se partagent mulSlots ; à lancer avec -race.
+	withMaxProcs(t)
+	want, _ := NewFibCalculator().Calculate(30000)
+	done := make(chan *big.Int)
+	for range 4 {
+		go func() {
+			v, _ := parallelCalculator().Calculate(30000)
+			done <- v
+		}()
+	}
+	for range 4 {
+		if v := <-done; v.Cmp(want) != 0 {
+			t.Error("résultat parallèle différent du calcul séquentiel")
+		}
+	}
+	if len(mulSlots) != 0 {
+		t.Errorf("%d places de mulSlots non libérées", len(mulSlots))
+	}
+}
+
+// BenchmarkMultiplyParallel compare le calcul de F(1 000 000) avec le produit
+// classique séquentiel et avec le produit parallèle.
+func BenchmarkMultiplyParallel(b *testing.B) {
+	withMaxProcs(b)
+	for _, bc := range []struct {
+		name string
+		fc   *FibCalculator
+	}{
+		{"sequentiel", NewFibCalculator()},
+		{"parallele", parallelCalculator()},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for range b.N {
+				if _, err := bc.fc.Calculate(1_000_000); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// readMetricsJSON exporte metrics par writeMetricsJSON puis relit le fichier.
+func readMetricsJSON(t *testing.T, metrics *Metrics, config Configuration) MetricsReport {
+	t.Helper()
+	metrics.EndTime = time.Now()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	if err := writeMetricsJSON(path, metrics.Report(config)); err != nil {
+		t.Fatalf("writeMetricsJSON : %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report MetricsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("métriques illisibles : %v\n%s", err, data)
+	}
+	return report
+}
+
+func TestMetricsJSONCountsComputedIndices(t *testing.T) {
+	config := DefaultConfig()
+	config.M, config.NumWorkers, config.SegmentSize = 2500, 2, 1000
+	metrics := NewMetrics()
+	sum := computeSum(context.Background(), config, metrics)
+
+	// Indices 0 à M-2 : segments [0, 999], [1000, 1999] et [2000, 2498].
+	want, a, b := new(big.Int), big.NewInt(0), big.NewInt(1)
+	for range config.M - 1 {
+		want.Add(want, a)
+		a.Add(a, b)
+		a, b = b, a
+	}
+	if sum.Cmp(want) != 0 {
+		t.Fatalf("somme incorrecte : %s, attendu %s", formatBigIntSci(sum), formatBigIntSci(want))
+	}
+
+	report := readMetricsJSON(t, metrics, config)
+	if report.TotalCalculations != int64(config.M-1) || report.Segments != 3 || report.Errors != 0 {
+		t.Errorf("calculs %d, segments %d, erreurs %d ; attendu %d, 3, 0",
+			report.TotalCalculations, report.Segments, report.Errors, config.M-1)
+	}
+	if report.M != config.M || report.NumWorkers != 2 || report.SegmentSize != 1000 || report.Algorithm != AlgorithmClassic {
+		t.Errorf("configuration exportée incorrecte : %+v", report)
+	}
+	if report.DurationNs <= 0 || report.AverageTimeNs != report.DurationNs/report.TotalCalculations {
+		t.Errorf("durée %d ns, moyenne %d ns incohérentes", report.DurationNs, report.AverageTimeNs)
+	}
+}
+
+func TestMetricsJSONCountsFailedSegments(t *testing.T) {
+	config := DefaultConfig()
+	config.M, config.NumWorkers, config.SegmentSize = 2500, 2, 1000
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	metrics := NewMetrics()
+	computeSum(ctx, config, metrics)
+
+	report := readMetricsJSON(t, metrics, config)
+	if report.TotalCalculations != 0 || report.Segments != 0 || report.Errors != 3 || report.AverageTimeNs != 0 {
+		t.Errorf("rapport après annulation : %+v ; attendu 3 segments en erreur et aucun calcul", report)
+	}
+}